@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// EmbedRequest is the body for Ollama's /api/embed endpoint
+type EmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbedResponse holds one embedding vector per input
+type EmbedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// Embed returns an embedding vector for each input text, in order
+func Embed(model string, inputs []string, timeout time.Duration) ([][]float64, error) {
+	jsonData, err := json.Marshal(EmbedRequest{Model: model, Input: inputs})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(
+		"http://localhost:11434/api/embed",
+		"application/json",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response EmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("ollama embed: %s", response.Error)
+	}
+	if len(response.Embeddings) != len(inputs) {
+		return nil, fmt.Errorf("ollama embed returned %d vectors for %d inputs", len(response.Embeddings), len(inputs))
+	}
+
+	return response.Embeddings, nil
+}
+
+// CosineSimilarity returns how closely two embedding vectors point the same way (-1 to 1)
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
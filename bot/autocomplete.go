@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/shop"
+)
+
+// maxAutocompleteChoices is Discord's limit on autocomplete suggestions
+const maxAutocompleteChoices = 25
+
+// handleItemAutocomplete suggests catalog and session special items as the player types,
+// hiding anything their character's level can't buy
+func handleItemAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := focusedValue(i.ApplicationCommandData().Options)
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		slog.Error("autocomplete failed to load catalog", "error", err)
+		respondWithChoices(s, i, nil)
+		return
+	}
+
+	// Characterless users see everything; /buy will turn them away anyway
+	level := 20
+	characterName := ""
+	if charFile, err := shop.GetCharacterForUser(getUsername(i)); err == nil {
+		if char, err := shop.LoadCharacter(charFile); err == nil {
+			level = shop.ParseLevel(char.ClassLevel)
+			characterName = char.Name
+		}
+	}
+	now := time.Now()
+
+	respondWithChoices(s, i, catalogChoices(catalog, query, func(item shop.Item) bool {
+		return shop.CanBuyAtLevel(item, level) && !item.SoldOut() && !item.HeldFromCharacter(characterName, now)
+	}))
+}
+
+// handleWishlistAutocomplete suggests items for /wishlist add. Nothing is hidden: sold out
+// and held items are exactly what players want Grash to watch for.
+func handleWishlistAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := focusedValue(i.ApplicationCommandData().Options)
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		slog.Error("autocomplete failed to load catalog", "error", err)
+		respondWithChoices(s, i, nil)
+		return
+	}
+
+	respondWithChoices(s, i, catalogChoices(catalog, query, func(shop.Item) bool { return true }))
+}
+
+// catalogChoices builds autocomplete suggestions for the catalog and session specials
+// matching a query, keeping only the items include accepts
+func catalogChoices(catalog *shop.Catalog, query string, include func(shop.Item) bool) []*discordgo.ApplicationCommandOptionChoice {
+	var candidates []shop.Item
+	if query == "" {
+		candidates = append(candidates, catalog.SessionSpecials.Items...)
+		candidates = append(candidates, catalog.Items...)
+	} else {
+		for _, r := range catalog.SearchItems(query, 0) {
+			candidates = append(candidates, r.Item)
+		}
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	seen := make(map[string]bool)
+	for _, item := range candidates {
+		// Specials come first, so a held or sold out special hides its catalog copy too
+		key := strings.ToLower(item.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !include(item) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateLabel(fmt.Sprintf("%s - %s gp", item.Name, shop.FormatCost(item.Cost)), 100),
+			Value: item.Name,
+		})
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+	}
+	return choices
+}
+
+// handleUseAutocomplete suggests the consumables the player's character is carrying
+func handleUseAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	query := strings.ToLower(focusedValue(i.ApplicationCommandData().Options))
+
+	charFile, err := shop.GetCharacterForUser(getUsername(i))
+	if err != nil {
+		respondWithChoices(s, i, nil)
+		return
+	}
+	char, err := shop.LoadCharacter(charFile)
+	if err != nil {
+		respondWithChoices(s, i, nil)
+		return
+	}
+	usable, err := shop.UsableItems(char)
+	if err != nil {
+		slog.Error("autocomplete failed to list consumables", "error", err)
+		respondWithChoices(s, i, nil)
+		return
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, item := range usable {
+		if query != "" && !strings.Contains(strings.ToLower(item.Name), query) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateLabel(fmt.Sprintf("%s (%d)", item.Name, item.Count), 100),
+			Value: item.Name,
+		})
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+	}
+
+	respondWithChoices(s, i, choices)
+}
+
+// focusedValue returns the value the user is typing, looking inside subcommands too
+func focusedValue(options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	for _, opt := range options {
+		if opt.Focused {
+			return opt.StringValue()
+		}
+		if len(opt.Options) > 0 {
+			if value := focusedValue(opt.Options); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// respondWithChoices sends autocomplete suggestions back to Discord
+func respondWithChoices(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		slog.Error("failed to send autocomplete choices", "error", err)
+	}
+}
@@ -13,7 +13,9 @@ import (
 )
 
 var BotToken string
-var GuildID string // Set for development (faster command registration), empty for global
+var GuildID string          // Set for development (faster command registration), empty for global
+var ShopChannelID string    // Optional: channel for shop announcements when a DM can't be sent
+var SpecialsSchedule string // Optional: cron expression for automatic specials refreshes
 
 var systemPrompt string = `You are Grash Ironledger, a grizzled female half-orc quartermaster who runs the supply depot for a band of adventurers.
 
@@ -100,6 +102,12 @@ func Run() {
 		}
 	}
 
+	// Let wishlisters know when the rotation turns over to something they want
+	go watchRotation(discord)
+
+	// Refresh specials automatically if a schedule is configured
+	startSpecialsScheduler(discord)
+
 	// keep the bot up until someone ctrl+c's it
 	fmt.Println("Grash Ironledger is ready for business. *sighs* Press CTRL-C to close shop...")
 	c := make(chan os.Signal, 1)
@@ -117,14 +125,23 @@ func Run() {
 	}
 }
 
-// interactionCreate handles slash command interactions
+// interactionCreate handles slash command, autocomplete and message component interactions
 func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	if i.Type != discordgo.InteractionApplicationCommand {
-		return
-	}
-
-	if handler, ok := CommandHandlers[i.ApplicationCommandData().Name]; ok {
-		handler(s, i)
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if handler, ok := CommandHandlers[i.ApplicationCommandData().Name]; ok {
+			handler(s, i)
+		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		if handler, ok := AutocompleteHandlers[i.ApplicationCommandData().Name]; ok {
+			handler(s, i)
+		}
+	case discordgo.InteractionMessageComponent:
+		// Custom IDs are "<prefix>:<args...>", route on the prefix
+		prefix, _, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+		if handler, ok := ComponentHandlers[prefix]; ok {
+			handler(s, i)
+		}
 	}
 }
 
@@ -2,6 +2,40 @@ package bot
 
 import "github.com/bwmarrin/discordgo"
 
+// shopCategoryChoices are the /shop categories, shared with the listing's category select menu
+var shopCategoryChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "All Items", Value: "all"},
+	{Name: "Weapons", Value: "weapons"},
+	{Name: "Armor", Value: "armor"},
+	{Name: "Potions", Value: "potions"},
+	{Name: "Adventuring Gear", Value: "gear"},
+	{Name: "Magic Weapons", Value: "magic_weapons"},
+	{Name: "Magic Armor", Value: "magic_armor"},
+	{Name: "Magic Potions", Value: "magic_potions"},
+	{Name: "Wondrous Items", Value: "wondrous"},
+	{Name: "Kits & Bundles", Value: "kits"},
+	{Name: "Session Specials", Value: "specials"},
+	{Name: "Monthly Rotation", Value: "monthly"},
+	{Name: "Last Week's Specials", Value: "previous"},
+}
+
+// saleCategoryChoices are the catalog categories a sale can be limited to
+var saleCategoryChoices = catalogCategoryChoices()[1:]
+
+// recommendCategoryChoices are the /shop categories /recommend can draw from
+var recommendCategoryChoices = catalogCategoryChoices()
+
+// catalogCategoryChoices returns the /shop categories backed by the catalog or live specials
+func catalogCategoryChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, choice := range shopCategoryChoices {
+		if choice.Value != "monthly" && choice.Value != "previous" {
+			choices = append(choices, choice)
+		}
+	}
+	return choices
+}
+
 // Commands defines all slash commands for the shop bot
 var Commands = []*discordgo.ApplicationCommand{
 	{
@@ -11,16 +45,9 @@ var Commands = []*discordgo.ApplicationCommand{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "category",
-				Description: "Filter by category (weapons, armor, potions, gear, specials)",
+				Description: "Filter by category (weapons, armor, potions, gear, magic, wondrous, kits, specials, monthly)",
 				Required:    false,
-				Choices: []*discordgo.ApplicationCommandOptionChoice{
-					{Name: "All Items", Value: "all"},
-					{Name: "Weapons", Value: "weapons"},
-					{Name: "Armor", Value: "armor"},
-					{Name: "Potions", Value: "potions"},
-					{Name: "Adventuring Gear", Value: "gear"},
-					{Name: "Session Specials", Value: "specials"},
-				},
+				Choices:     shopCategoryChoices,
 			},
 		},
 	},
@@ -29,10 +56,11 @@ var Commands = []*discordgo.ApplicationCommand{
 		Description: "Purchase an item from the shop",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionString,
-				Name:        "item",
-				Description: "Name of the item to purchase",
-				Required:    true,
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "item",
+				Description:  "Name of the item to purchase",
+				Required:     true,
+				Autocomplete: true,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionInteger,
@@ -44,6 +72,155 @@ var Commands = []*discordgo.ApplicationCommand{
 			},
 		},
 	},
+	{
+		Name:        "haggle",
+		Description: "Try to talk Grash down on an item with a Persuasion check",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "item",
+				Description:  "Name of the item to haggle for",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "roll",
+				Description: "Your Persuasion check total, if you rolled it yourself (default: Grash rolls for you)",
+				Required:    false,
+				MinValue:    floatPtr(1),
+				MaxValue:    50,
+			},
+		},
+	},
+	{
+		Name:        "item",
+		Description: "Look at one item's full stats and get Grash's opinion",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "name",
+				Description:  "Name of the item to inspect",
+				Required:     true,
+				Autocomplete: true,
+			},
+		},
+	},
+	{
+		Name:        "compare",
+		Description: "Compare two or three items side by side",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "first",
+				Description:  "First item to compare",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "second",
+				Description:  "Second item to compare",
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "third",
+				Description:  "Optional third item to compare",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "verdict",
+				Description: "Ask Grash which one you should take (default: false)",
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:        "recommend",
+		Description: "Ask Grash for three picks that suit your character",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "budget",
+				Description: "Most you want to spend per item, in gp (default: no limit)",
+				Required:    false,
+				MinValue:    floatPtr(0),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "category",
+				Description: "Only recommend from this category (default: all)",
+				Required:    false,
+				Choices:     recommendCategoryChoices,
+			},
+		},
+	},
+	{
+		Name:        "plan",
+		Description: "Have Grash put together a shopping list for an adventure",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "budget",
+				Description: "Total gold to spend, in gp",
+				Required:    true,
+				MinValue:    floatPtr(1),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "goal",
+				Description: "What you're gearing up for, e.g. \"dungeon crawl\" or \"long journey\"",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "max_weight",
+				Description: "Most the list can weigh, in lb (default: 50)",
+				Required:    false,
+				MinValue:    floatPtr(1),
+				MaxValue:    100,
+			},
+		},
+	},
+	{
+		Name:        "roll",
+		Description: "Roll dice, e.g. 1d20+5, 2d6+3, 4d6kh3 or d20 adv",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "dice",
+				Description: "Dice expression: NdM, +/- modifiers, khN/klN to keep highest/lowest",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mode",
+				Description: "Roll the d20 with advantage or disadvantage",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Advantage", Value: "advantage"},
+					{Name: "Disadvantage", Value: "disadvantage"},
+				},
+			},
+		},
+	},
+	{
+		Name:        "use",
+		Description: "Use up a potion, scroll or other consumable from your inventory",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "item",
+				Description:  "Consumable to use",
+				Required:     true,
+				Autocomplete: true,
+			},
+		},
+	},
 	{
 		Name:        "inventory",
 		Description: "View your character's current inventory",
@@ -52,9 +229,234 @@ var Commands = []*discordgo.ApplicationCommand{
 		Name:        "history",
 		Description: "View your purchase history",
 	},
+	{
+		Name:        "wishlist",
+		Description: "Ask Grash to keep an eye out for items",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add an item to your wishlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "item",
+						Description:  "Name of the item you want",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove an item from your wishlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "item",
+						Description: "Name of the item to remove",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "View your wishlist",
+			},
+		},
+	},
 	{
 		Name:        "refresh",
-		Description: "Refresh session specials with AI-curated items (GM only)",
+		Description: "Draft new AI-curated session specials to review before publishing (GM only)",
+	},
+	{
+		Name:        "gm",
+		Description: "Game master tools (GM only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "holds",
+				Description: "Manage holds on session specials",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List specials currently on hold",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "release",
+						Description: "Release holds so anyone can buy (all holds if no item given)",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "item",
+								Description: "Name of the held special to release",
+								Required:    false,
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "purchases",
+				Description: "Confirm purchases into character inventories",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "pending",
+						Description: "List purchases not yet added to inventories",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "approve",
+						Description: "Add a character's pending purchases to their inventory",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "character",
+								Description: "Character name or file name",
+								Required:    true,
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "sales",
+				Description: "Run sale events",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List running sales",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "start",
+						Description: "Start a sale on everything, a category, or one item",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "name",
+								Description: "Name of the sale, e.g. \"Midsummer Market\"",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionNumber,
+								Name:        "percent",
+								Description: "Percent off",
+								Required:    true,
+								MinValue:    floatPtr(1),
+								MaxValue:    90,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "category",
+								Description: "Only this category (default: everything)",
+								Required:    false,
+								Choices:     saleCategoryChoices,
+							},
+							{
+								Type:         discordgo.ApplicationCommandOptionString,
+								Name:         "item",
+								Description:  "Only this item",
+								Required:     false,
+								Autocomplete: true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionNumber,
+								Name:        "days",
+								Description: "How long it runs (default: until ended)",
+								Required:    false,
+								MinValue:    floatPtr(0.5),
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "end",
+						Description: "End a sale",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "name",
+								Description: "Name of the sale to end",
+								Required:    true,
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "reputation",
+				Description: "Set how Grash feels about each character",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "List each character's reputation with Grash",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "set",
+						Description: "Set a character's reputation (positive means better prices)",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "character",
+								Description: "Character name or file name",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "value",
+								Description: "Reputation, -5 to 5",
+								Required:    true,
+								MinValue:    floatPtr(-5),
+								MaxValue:    5,
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "specials",
+				Description: "Review and restore archived specials lineups",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "history",
+						Description: "List archived specials lineups",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "rollback",
+						Description: "Make an archived lineup the live specials again",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "version",
+								Description: "Archive version to restore (see /gm specials history)",
+								Required:    true,
+								MinValue:    floatPtr(1),
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "runs",
+						Description: "List recent scheduled specials refreshes",
+					},
+				},
+			},
+		},
 	},
 }
 
@@ -62,9 +464,42 @@ var Commands = []*discordgo.ApplicationCommand{
 var CommandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
 	"shop":      handleShop,
 	"buy":       handleBuy,
+	"haggle":    handleHaggle,
+	"item":      handleItem,
+	"compare":   handleCompare,
+	"recommend": handleRecommend,
+	"plan":      handlePlan,
+	"roll":      handleRoll,
+	"use":       handleUse,
 	"inventory": handleInventory,
 	"history":   handleHistory,
+	"wishlist":  handleWishlist,
 	"refresh":   handleRefresh,
+	"gm":        handleGM,
+}
+
+// AutocompleteHandlers maps command names to their autocomplete handlers
+var AutocompleteHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+	"buy":      handleItemAutocomplete,
+	"haggle":   handleItemAutocomplete,
+	"item":     handleItemAutocomplete,
+	"compare":  handleItemAutocomplete,
+	"wishlist": handleWishlistAutocomplete,
+	"gm":       handleItemAutocomplete,
+	"use":      handleUseAutocomplete,
+}
+
+// ComponentHandlers maps component custom ID prefixes (before the first ":") to their handlers
+var ComponentHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+	"buy":           handleBuyPick,
+	"item":          handleItemPick,
+	"shop_page":     handleShopPage,
+	"shop_category": handleShopCategory,
+	"draft":         handleDraftAction,
+	"draft_swap":    handleDraftSwap,
+	"draft_swap_to": handleDraftSwapTo,
+	"plan":          handlePlanAction,
+	"roll":          handleRollButton,
 }
 
 // floatPtr is a helper to create a *float64 for MinValue
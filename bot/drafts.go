@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/shop"
+)
+
+// draftNotice heads every draft view so the GM knows players can't see it yet
+const draftNotice = "**Specials Draft** - only you can see this. Nothing goes live until you publish."
+
+// maxRerollButtons caps the per-character re-roll buttons (3 rows of 5)
+const maxRerollButtons = 15
+
+// buildDraftView renders a specials draft grouped by character, with its review controls.
+// Custom IDs: "draft:<action>:<draft id>[:<character index>]", "draft_swap:<draft id>"
+func buildDraftView(draft *shop.SpecialsDraft) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	prices := make(map[string]shop.Item)
+	for _, item := range draft.Items() {
+		prices[strings.ToLower(item.Name)] = item
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Session Specials Draft",
+		Color: mundaneColor,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Draft %s • prompt %s", draft.ID, draft.PromptHash),
+		},
+	}
+
+	picks := draft.Picks()
+	byCharacter := make(map[string][]string)
+	var order []string
+	for _, pick := range picks {
+		if _, ok := byCharacter[pick.Character]; !ok {
+			order = append(order, pick.Character)
+		}
+		line := "• **" + pick.Item.Name + "**"
+		if item, ok := prices[strings.ToLower(pick.Item.Name)]; ok {
+			line += fmt.Sprintf(" - %s gp (%s)", shop.FormatCost(item.Cost), item.Rarity)
+		}
+		if pick.Item.Reason != "" {
+			line += "\n  *" + pick.Item.Reason + "*"
+		}
+		byCharacter[pick.Character] = append(byCharacter[pick.Character], line)
+	}
+	for _, name := range order {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  truncateLabel(name, 256),
+			Value: truncateLabel(strings.Join(byCharacter[name], "\n"), 1024),
+		})
+	}
+	if len(picks) == 0 {
+		embed.Description = "The curator didn't pick anything. Re-roll or discard."
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Publish",
+				Style:    discordgo.SuccessButton,
+				CustomID: "draft:publish:" + draft.ID,
+				Disabled: len(picks) == 0,
+			},
+			discordgo.Button{
+				Label:    "Discard",
+				Style:    discordgo.DangerButton,
+				CustomID: "draft:discard:" + draft.ID,
+			},
+		}},
+	}
+
+	if len(picks) > 0 {
+		var options []discordgo.SelectMenuOption
+		for _, pick := range picks[:min(len(picks), 25)] {
+			options = append(options, discordgo.SelectMenuOption{
+				Label: truncateLabel(fmt.Sprintf("%s (%s)", pick.Item.Name, pick.Character), 100),
+				Value: strconv.Itoa(pick.Index),
+			})
+		}
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    "draft_swap:" + draft.ID,
+				Placeholder: "Swap out an item",
+				Options:     options,
+			},
+		}})
+	}
+
+	var row []discordgo.MessageComponent
+	characters := draft.Characters()
+	for idx, name := range characters[:min(len(characters), maxRerollButtons)] {
+		row = append(row, discordgo.Button{
+			Label:    truncateLabel("Re-roll "+name, 80),
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("draft:reroll:%s:%d", draft.ID, idx),
+		})
+		if len(row) == 5 {
+			components = append(components, discordgo.ActionsRow{Components: row})
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		components = append(components, discordgo.ActionsRow{Components: row})
+	}
+
+	return embed, components
+}
+
+// handleDraftAction processes the Publish, Discard, Re-roll and Back buttons on a draft
+func handleDraftAction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.SplitN(i.MessageComponentData().CustomID, ":", 4)
+	if len(parts) < 3 {
+		slog.Error("malformed draft button", "custom_id", i.MessageComponentData().CustomID)
+		return
+	}
+	action, draftID := parts[1], parts[2]
+	if !isGM(getUsername(i)) {
+		return
+	}
+
+	slog.Info("draft action received", "action", action, "draft", draftID, "user", getUsername(i))
+
+	switch action {
+	case "discard":
+		shop.DiscardDraft(draftID)
+		updateComponentMessage(s, i, "Draft discarded. The live specials are unchanged.", nil, []discordgo.MessageComponent{})
+
+	case "view":
+		draft, err := shop.GetDraft(draftID)
+		if err != nil {
+			updateComponentMessage(s, i, "Error: "+err.Error(), nil, []discordgo.MessageComponent{})
+			return
+		}
+		embed, components := buildDraftView(draft)
+		updateComponentMessage(s, i, draftNotice, embed, components)
+
+	case "publish":
+		if err := deferComponentUpdate(s, i); err != nil {
+			return
+		}
+		items, err := shop.PublishDraft(draftID)
+		if err != nil {
+			editComponentMessage(s, i, "Error publishing specials: "+err.Error(), nil, []discordgo.MessageComponent{})
+			return
+		}
+		editComponentMessage(s, i, fmt.Sprintf("**Session Specials Published!** (%d items)", len(items)), nil, []discordgo.MessageComponent{})
+
+		announceSpecials(s, i.ChannelID, items)
+		notifyWishlists(s, items, "Session Specials", "specials:"+time.Now().Format(time.RFC3339))
+
+	case "reroll":
+		characterIndex, err := strconv.Atoi(parts[len(parts)-1])
+		if len(parts) != 4 || err != nil {
+			slog.Error("malformed draft re-roll button", "custom_id", i.MessageComponentData().CustomID)
+			return
+		}
+		// Re-rolling calls Ollama again, so acknowledge first
+		if err := deferComponentUpdate(s, i); err != nil {
+			return
+		}
+		draft, err := shop.RerollCharacter(draftID, characterIndex)
+		if err != nil {
+			editComponentMessage(s, i, "Error re-rolling: "+err.Error(), nil, []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{backToDraftButton(draftID)}},
+			})
+			return
+		}
+		embed, components := buildDraftView(draft)
+		editComponentMessage(s, i, draftNotice, embed, components)
+	}
+}
+
+// handleDraftSwap shows replacement options for the pick chosen in the swap menu.
+// Custom ID of the follow-up menu: "draft_swap_to:<draft id>:<pick index>"
+func handleDraftSwap(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	_, draftID, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+	values := i.MessageComponentData().Values
+	if len(values) == 0 || !isGM(getUsername(i)) {
+		return
+	}
+	index, err := strconv.Atoi(values[0])
+	if err != nil {
+		slog.Error("malformed draft swap value", "value", values[0])
+		return
+	}
+
+	draft, err := shop.GetDraft(draftID)
+	if err != nil {
+		updateComponentMessage(s, i, "Error: "+err.Error(), nil, []discordgo.MessageComponent{})
+		return
+	}
+
+	back := discordgo.ActionsRow{Components: []discordgo.MessageComponent{backToDraftButton(draftID)}}
+	candidates := draft.SwapCandidates(index, 25)
+	if len(candidates) == 0 {
+		updateComponentMessage(s, i, "Nothing else of that rarity is eligible for this lineup.", nil, []discordgo.MessageComponent{back})
+		return
+	}
+
+	var options []discordgo.SelectMenuOption
+	for _, item := range candidates {
+		options = append(options, discordgo.SelectMenuOption{
+			Label: truncateLabel(item.Name, 100),
+			Value: truncateLabel(item.Name, 100),
+		})
+	}
+
+	picks := draft.Picks()
+	content := fmt.Sprintf("What should replace **%s** for %s?", picks[index].Item.Name, picks[index].Character)
+	updateComponentMessage(s, i, content, nil, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    fmt.Sprintf("draft_swap_to:%s:%d", draftID, index),
+				Placeholder: "Choose a replacement",
+				Options:     options,
+			},
+		}},
+		back,
+	})
+}
+
+// handleDraftSwapTo applies the replacement chosen for a draft pick
+func handleDraftSwapTo(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	values := i.MessageComponentData().Values
+	if len(parts) != 3 || len(values) == 0 || !isGM(getUsername(i)) {
+		return
+	}
+	index, err := strconv.Atoi(parts[2])
+	if err != nil {
+		slog.Error("malformed draft swap menu", "custom_id", i.MessageComponentData().CustomID)
+		return
+	}
+
+	draft, err := shop.SwapItem(parts[1], index, values[0])
+	if err != nil {
+		updateComponentMessage(s, i, "Error: "+err.Error(), nil, []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{backToDraftButton(parts[1])}},
+		})
+		return
+	}
+
+	embed, components := buildDraftView(draft)
+	updateComponentMessage(s, i, draftNotice, embed, components)
+}
+
+// backToDraftButton returns to the full draft view
+func backToDraftButton(draftID string) discordgo.Button {
+	return discordgo.Button{
+		Label:    "Back to draft",
+		Style:    discordgo.SecondaryButton,
+		CustomID: "draft:view:" + draftID,
+	}
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/shop"
+)
+
+// shopPageSize is how many items each /shop embed page shows (Discord allows 25 fields)
+const shopPageSize = 10
+
+// mundaneColor is the embed colour for pages with no magic items
+const mundaneColor = 0x8b5a2b
+
+// rarityColors maps rarity rank (see shop.RarityRank) to embed colours
+var rarityColors = []int{
+	0x9d9d9d, // Common
+	0x1eff00, // Uncommon
+	0x0070dd, // Rare
+	0xa335ee, // Very Rare
+	0xff8000, // Legendary
+	0xe6cc80, // Artifact
+}
+
+// buildShopPage renders one page of a /shop listing as an embed with navigation components.
+// All page state lives in the component custom IDs, so any click can rebuild the page from scratch.
+func buildShopPage(category, title string, items []shop.Item, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	pages := (len(items) + shopPageSize - 1) / shopPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	page = max(0, min(page, pages-1))
+
+	start := page * shopPageSize
+	end := min(start+shopPageSize, len(items))
+
+	embed := &discordgo.MessageEmbed{
+		Title: title,
+		Color: mundaneColor,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d • %d items", page+1, pages, len(items)),
+		},
+	}
+	if len(items) == 0 {
+		embed.Description = "No items found."
+	}
+
+	// Colour the page by its rarest item
+	highest := -1
+	for _, item := range items[start:end] {
+		embed.Fields = append(embed.Fields, itemField(item))
+		if rank := shop.RarityRank(item.Rarity); rank > highest {
+			highest = rank
+		}
+	}
+	if highest >= 0 && highest < len(rarityColors) {
+		embed.Color = rarityColors[highest]
+	}
+
+	return embed, shopPageComponents(category, page, pages)
+}
+
+// itemField renders a single item as an embed field
+func itemField(item shop.Item) *discordgo.MessageEmbedField {
+	name := fmt.Sprintf("%s - %s gp", item.Name, shop.FormatCost(item.Cost))
+	if item.Rarity != "" {
+		name += fmt.Sprintf(" (%s)", item.Rarity)
+	}
+	if stock := item.StockLabel(); stock != "" {
+		name += " • " + stock
+	}
+
+	value := shop.ItemDetails(item)
+	if hold := item.HoldLabel(time.Now()); hold != "" {
+		value = fmt.Sprintf("*%s*\n%s", hold, value)
+	}
+	if value == "" {
+		value = "\u200b" // Discord rejects empty field values
+	}
+
+	return &discordgo.MessageEmbedField{
+		Name:  truncateLabel(name, 256),
+		Value: truncateLabel(value, 300),
+	}
+}
+
+// shopPageComponents builds the category select menu and Previous/Next buttons.
+// Custom IDs: "shop_category" and "shop_page:<category>:<page>"
+func shopPageComponents(category string, page, pages int) []discordgo.MessageComponent {
+	var options []discordgo.SelectMenuOption
+	for _, choice := range shopCategoryChoices {
+		value := fmt.Sprint(choice.Value)
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   choice.Name,
+			Value:   value,
+			Default: value == category,
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    "shop_category",
+				Placeholder: "Browse another category",
+				Options:     options,
+			},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Previous",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("shop_page:%s:%d", category, page-1),
+				Disabled: page == 0,
+			},
+			discordgo.Button{
+				Label:    "Next",
+				Style:    discordgo.SecondaryButton,
+				CustomID: fmt.Sprintf("shop_page:%s:%d", category, page+1),
+				Disabled: page >= pages-1,
+			},
+		}},
+	}
+}
+
+// handleShopPage processes the Previous/Next buttons on a /shop listing
+func handleShopPage(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.Split(i.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 {
+		slog.Error("malformed shop page button", "custom_id", i.MessageComponentData().CustomID)
+		return
+	}
+	page, err := strconv.Atoi(parts[2])
+	if err != nil {
+		page = 0
+	}
+
+	// Keep Grash's flavor text when flipping pages
+	content := ""
+	if i.Message != nil {
+		content = i.Message.Content
+	}
+	updateShopListing(s, i, parts[1], page, content)
+}
+
+// handleShopCategory processes the category select menu on a /shop listing
+func handleShopCategory(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+	updateShopListing(s, i, values[0], 0, "")
+}
+
+// updateShopListing rebuilds a /shop listing in place for the given category and page
+func updateShopListing(s *discordgo.Session, i *discordgo.InteractionCreate, category string, page int, content string) {
+	slog.Info("shop listing update", "category", category, "page", page, "user", getUsername(i))
+
+	title, items, err := shopListing(category)
+	if err != nil {
+		content = "Error: " + err.Error()
+	}
+	embed, components := buildShopPage(category, title, items, page)
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		slog.Error("failed to update shop listing", "error", err)
+	}
+}
+
+// buildItemEmbed renders every known field of an item as a detail embed
+func buildItemEmbed(item shop.Item) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       item.Name,
+		Description: truncateLabel(item.Description, 4096),
+		Color:       mundaneColor,
+	}
+	if rank := shop.RarityRank(item.Rarity); rank >= 0 && rank < len(rarityColors) {
+		embed.Color = rarityColors[rank]
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"Cost", shop.FormatCost(item.Cost) + " gp"},
+		{"Category", shop.CategoryTitle(item.Category)},
+		{"Rarity", item.Rarity},
+		{"Attunement", item.Attunement},
+		{"Type", item.Type},
+		{"Damage", item.Damage},
+		{"Properties", item.Properties},
+		{"Mastery", item.Mastery},
+		{"AC", item.AC},
+		{"Strength", item.Strength},
+		{"Stealth", item.Stealth},
+		{"Weight", item.Weight},
+		{"Stock", item.StockLabel()},
+	}
+	for _, f := range fields {
+		// Skip blanks and the "—" placeholder the PHB tables use
+		if f.value == "" || f.value == "—" {
+			continue
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   f.name,
+			Value:  truncateLabel(f.value, 1024),
+			Inline: true,
+		})
+	}
+
+	return embed
+}
@@ -1,12 +1,17 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/config"
+	"github.com/egotch/dnd-shopkeep/dice"
 	"github.com/egotch/dnd-shopkeep/shop"
 )
 
@@ -22,6 +27,18 @@ func getUsername(i *discordgo.InteractionCreate) string {
 	return "unknown"
 }
 
+// getUserID extracts the Discord user ID from an interaction, handling both
+// guild (Member) and DM (User) contexts
+func getUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
 // handleShop processes the /shop command
 func handleShop(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
@@ -30,7 +47,639 @@ func handleShop(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		category = options[0].StringValue()
 	}
 
-	slog.Info("shop command received", "category", category, "user", getUsername(i))
+	slog.Info("shop command received", "category", category, "user", getUsername(i))
+
+	// Defer response immediately - Ollama calls can be slow
+	if err := deferResponse(s, i); err != nil {
+		slog.Error("failed to defer response", "error", err)
+		return
+	}
+
+	title, items, err := shopListing(category)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: "+err.Error())
+		return
+	}
+
+	if len(items) == 0 {
+		editDeferredResponse(s, i, fmt.Sprintf("No items found in category: %s", category))
+		return
+	}
+
+	// Get character context for personalized recommendations
+	charFile, _ := shop.GetCharacterForUser(getUsername(i))
+	char, _ := shop.LoadCharacter(charFile)
+
+	// Build response with AI flavor if available
+	var response string
+	if char != nil {
+		// Send to Ollama for quartermaster flavor
+		prompt := fmt.Sprintf("[%s]: Show me %s items", char.Name, category)
+		conv.AddMessage("user", prompt)
+		slog.Info("sending to ollama", "prompt", prompt)
+		start := time.Now()
+		aiResponse, err := conv.SendToOllama()
+		slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+		if err == nil {
+			response = aiResponse
+		}
+	}
+
+	embed, components := buildShopPage(category, title, items, 0)
+
+	slog.Info("sending shop response", "category", category, "item_count", len(items))
+	editDeferredEmbed(s, i, response, embed, components)
+}
+
+// shopListing loads the title and items for a /shop category, hiding
+// catalog items above the party's level
+func shopListing(category string) (string, []shop.Item, error) {
+	title := shop.CategoryTitle(category)
+
+	switch category {
+	case "specials":
+		return title, shop.GetSessionSpecials(), nil
+	case "previous":
+		snapshot, err := shop.PreviousSpecials()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load the specials archive: %w", err)
+		}
+		if snapshot == nil {
+			return title, nil, nil
+		}
+		return fmt.Sprintf("%s (v%d)", title, snapshot.Version), snapshot.Items, nil
+	case "monthly":
+		items, err := shop.GetCurrentRotation()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load %s: %w", shop.CurrentRotationLabel(), err)
+		}
+		return fmt.Sprintf("%s (%s)", title, shop.CurrentRotationPeriod()), items, nil
+	}
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	// Hide magic items the party isn't ready for yet
+	return title, shop.FilterShopItemsByLevel(catalog.GetItemsByCategory(category), shop.PartyLevel()), nil
+}
+
+// handleBuy processes the /buy command
+func handleBuy(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	itemName := ""
+	quantity := 1
+
+	for _, opt := range options {
+		switch opt.Name {
+		case "item":
+			itemName = opt.StringValue()
+		case "quantity":
+			quantity = int(opt.IntValue())
+		}
+	}
+
+	slog.Info("buy command received", "item", itemName, "quantity", quantity, "user", getUsername(i))
+
+	// Defer response immediately - Ollama calls can be slow
+	if err := deferResponse(s, i); err != nil {
+		slog.Error("failed to defer response", "error", err)
+		return
+	}
+
+	// Get character for this user
+	charFile, err := shop.GetCharacterForUser(getUsername(i))
+	if err != nil {
+		editDeferredResponse(s, i, "Error: You don't have a character registered. Contact the GM.")
+		return
+	}
+
+	char, err := shop.LoadCharacter(charFile)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load character: "+err.Error())
+		return
+	}
+
+	// Find the item in catalog
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load catalog: "+err.Error())
+		return
+	}
+
+	item, err := catalog.FindItem(itemName)
+	if err != nil {
+		var ambiguous *shop.AmbiguousItemError
+		if errors.As(err, &ambiguous) {
+			askWhichItem(s, i, ambiguous, quantity)
+			return
+		}
+		editDeferredResponse(s, i, fmt.Sprintf("Error: Item '%s' not found. Try /shop to see available items.", itemName))
+		return
+	}
+
+	completePurchase(s, i, charFile, char, item, quantity)
+}
+
+// askWhichItem replaces the deferred /buy response with a button per candidate item
+func askWhichItem(s *discordgo.Session, i *discordgo.InteractionCreate, ambiguous *shop.AmbiguousItemError, quantity int) {
+	slog.Info("ambiguous item search", "query", ambiguous.Query, "candidates", len(ambiguous.Candidates))
+
+	var buttons []discordgo.MessageComponent
+	for _, item := range ambiguous.Candidates {
+		buttons = append(buttons, discordgo.Button{
+			Label:    truncateLabel(fmt.Sprintf("%s (%s gp)", item.Name, shop.FormatCost(item.Cost)), 80),
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("buy:%d:%s", quantity, item.Name),
+		})
+	}
+
+	content := fmt.Sprintf("*Grash taps the ledger.* \"'%s'? I've got more than one of those. Which one?\"", ambiguous.Query)
+	editDeferredWithComponents(s, i, content, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: buttons},
+	})
+}
+
+// handleBuyPick processes a button click from the /buy disambiguation prompt.
+// The custom ID carries everything we need: "buy:<quantity>:<item name>"
+func handleBuyPick(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.SplitN(i.MessageComponentData().CustomID, ":", 3)
+	if len(parts) != 3 {
+		slog.Error("malformed buy button", "custom_id", i.MessageComponentData().CustomID)
+		return
+	}
+	quantity, err := strconv.Atoi(parts[1])
+	if err != nil || quantity < 1 {
+		quantity = 1
+	}
+	itemName := parts[2]
+
+	slog.Info("buy pick received", "item", itemName, "quantity", quantity, "user", getUsername(i))
+
+	// Acknowledge the click - the purchase edits this same message
+	if err := deferComponentUpdate(s, i); err != nil {
+		return
+	}
+
+	charFile, err := shop.GetCharacterForUser(getUsername(i))
+	if err != nil {
+		editDeferredWithComponents(s, i, "Error: You don't have a character registered. Contact the GM.", []discordgo.MessageComponent{})
+		return
+	}
+
+	char, err := shop.LoadCharacter(charFile)
+	if err != nil {
+		editDeferredWithComponents(s, i, "Error: Failed to load character: "+err.Error(), []discordgo.MessageComponent{})
+		return
+	}
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		editDeferredWithComponents(s, i, "Error: Failed to load catalog: "+err.Error(), []discordgo.MessageComponent{})
+		return
+	}
+
+	item, err := catalog.FindItem(itemName)
+	if err != nil {
+		editDeferredWithComponents(s, i, fmt.Sprintf("Error: Item '%s' is no longer available.", itemName), []discordgo.MessageComponent{})
+		return
+	}
+
+	completePurchase(s, i, charFile, char, item, quantity)
+}
+
+// completePurchase records the purchase, asks Grash for flavor and edits the deferred response
+func completePurchase(s *discordgo.Session, i *discordgo.InteractionCreate, charFile string, char *shop.Character, item *shop.Item, quantity int) {
+	// Record the purchase - fails cleanly if limited stock has run out
+	quote, err := shop.PurchaseItem(charFile, *item, quantity, "Between sessions")
+	if err != nil {
+		editDeferredWithComponents(s, i, purchaseErrorMessage(err), []discordgo.MessageComponent{})
+		return
+	}
+
+	// Sold anyway, but tell them if their class can't use it
+	warnings := shop.ClassWarnings(char, *item)
+
+	// Generate AI response for flavor
+	prompt := fmt.Sprintf("[%s]: I want to buy %d %s", char.Name, quantity, item.Name)
+	if len(warnings) > 0 {
+		prompt += fmt.Sprintf(" (though %s)", strings.Join(warnings, "; "))
+	}
+	conv.AddMessage("user", prompt)
+	slog.Info("sending to ollama", "prompt", prompt)
+	start := time.Now()
+	aiResponse, err := conv.SendToOllama()
+	slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+
+	var response string
+	if err == nil && aiResponse != "" {
+		response = aiResponse + "\n\n"
+	}
+
+	response += fmt.Sprintf("**Purchase Recorded!**\n• Item: %s (x%d)\n• Total: %s gp\n", item.Name, quantity, shop.FormatCost(quote.Total))
+	if len(quote.Modifiers) > 0 {
+		response += fmt.Sprintf("• Price: %s gp each, list %s gp (%s)\n",
+			shop.FormatCost(quote.Unit), shop.FormatCost(quote.Base), shop.FormatModifiers(quote.Modifiers))
+	}
+	response += fmt.Sprintf("• Character: %s\n\n*Remember to deduct gold from your character sheet!*", char.Name)
+	for _, warning := range warnings {
+		response += fmt.Sprintf("\n⚠️ %s.", warning)
+	}
+
+	slog.Info("purchase recorded", "item", item.Name, "quantity", quantity, "character", char.Name)
+	editDeferredWithComponents(s, i, response, []discordgo.MessageComponent{})
+}
+
+// handleHaggle processes the /haggle command: a Persuasion check against Grash for a personal price
+func handleHaggle(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	itemName := ""
+	roll := 0
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "item":
+			itemName = opt.StringValue()
+		case "roll":
+			roll = int(opt.IntValue())
+		}
+	}
+
+	slog.Info("haggle command received", "item", itemName, "roll", roll, "user", getUsername(i))
+
+	// Defer response immediately - Ollama calls can be slow
+	if err := deferResponse(s, i); err != nil {
+		slog.Error("failed to defer response", "error", err)
+		return
+	}
+
+	charFile, err := shop.GetCharacterForUser(getUsername(i))
+	if err != nil {
+		editDeferredResponse(s, i, "Error: You don't have a character registered. Contact the GM.")
+		return
+	}
+	char, err := shop.LoadCharacter(charFile)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load character: "+err.Error())
+		return
+	}
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load catalog: "+err.Error())
+		return
+	}
+	item, err := catalog.FindItem(itemName)
+	if err != nil {
+		editDeferredResponse(s, i, fmt.Sprintf("Error: Item '%s' not found. Try /shop to see available items.", itemName))
+		return
+	}
+	if item.SoldOut() {
+		editDeferredResponse(s, i, fmt.Sprintf("*Grash snorts.* \"Haggle all you like, '%s' is sold out.\"", item.Name))
+		return
+	}
+
+	result, err := shop.Haggle(charFile, char, *item, roll)
+	if err != nil {
+		var already *shop.AlreadyHaggledError
+		if errors.As(err, &already) {
+			editDeferredResponse(s, i, fmt.Sprintf("*Grash folds her arms.* \"We've been over this. %s.\"", already.Error()))
+			return
+		}
+		var tooLow *shop.LevelTooLowError
+		if errors.As(err, &tooLow) {
+			editDeferredResponse(s, i, purchaseErrorMessage(err))
+			return
+		}
+		editDeferredResponse(s, i, "Error: "+err.Error())
+		return
+	}
+
+	// Grash reacts to the outcome; the numbers are already decided
+	outcome := "I failed - she won't budge on the price"
+	switch {
+	case result.Percent < 0:
+		outcome = fmt.Sprintf("I badly insulted her - she's raising my price by %s%%", shop.FormatCost(-result.Percent))
+	case result.Success:
+		outcome = fmt.Sprintf("I succeeded - she grudgingly knocks %s%% off", shop.FormatCost(result.Percent))
+	}
+	prompt := fmt.Sprintf("[%s]: I try to haggle you down on %s (listed at %s gp). My Persuasion check: %d against DC %d. %s. React in character.",
+		char.Name, item.Name, shop.FormatCost(item.Cost), result.Total, result.DC, outcome)
+	conv.AddMessage("user", prompt)
+	slog.Info("sending to ollama", "prompt", prompt)
+	start := time.Now()
+	aiResponse, err := conv.SendToOllama()
+	slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+
+	var response string
+	if err == nil && aiResponse != "" {
+		response = aiResponse + "\n\n"
+	}
+
+	check := fmt.Sprintf("%d", result.Total)
+	if result.Natural > 0 {
+		check = fmt.Sprintf("%d (d20) %+d = %d", result.Natural, result.Bonus, result.Total)
+	}
+	verdict := "Failed"
+	if result.Success {
+		verdict = "Success"
+	}
+	response += fmt.Sprintf("🎲 **Persuasion:** %s vs DC %d - **%s**\n", check, result.DC, verdict)
+
+	until := result.Deal.Until
+	if t, err := time.Parse(time.RFC3339, result.Deal.Until); err == nil {
+		until = t.Local().Format("Mon Jan 2 15:04")
+	}
+	response += fmt.Sprintf("Your price: **%s gp** (list %s gp), good until %s.", shop.FormatCost(result.Quote.Unit), shop.FormatCost(item.Cost), until)
+	if len(result.Quote.Modifiers) > 0 {
+		response += fmt.Sprintf("\n*%s*", shop.FormatModifiers(result.Quote.Modifiers))
+	}
+
+	slog.Info("haggle resolved", "item", item.Name, "character", char.Name, "total", result.Total, "dc", result.DC, "percent", result.Percent)
+	editDeferredWithComponents(s, i, response, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    truncateLabel(fmt.Sprintf("Buy %s (%s gp)", item.Name, shop.FormatCost(result.Quote.Unit)), 80),
+				Style:    discordgo.PrimaryButton,
+				CustomID: fmt.Sprintf("buy:1:%s", item.Name),
+			},
+		}},
+	})
+}
+
+// purchaseErrorMessage turns a failed purchase into Grash's reply
+func purchaseErrorMessage(err error) string {
+	var soldOut *shop.SoldOutError
+	if errors.As(err, &soldOut) {
+		return fmt.Sprintf("*Grash doesn't even look up.* \"Sorry, %s.\"", soldOut.Error())
+	}
+	var held *shop.HeldItemError
+	if errors.As(err, &held) {
+		return fmt.Sprintf("*Grash slaps a hand on the ledger.* \"Hands off. %s.\"", held.Error())
+	}
+	var tooLow *shop.LevelTooLowError
+	if errors.As(err, &tooLow) {
+		return fmt.Sprintf("*Grash pulls it back across the counter.* \"Not yet. %s.\"", tooLow.Error())
+	}
+	return "Error: Failed to record purchase: " + err.Error()
+}
+
+// handleItem processes the /item command
+func handleItem(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	itemName := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "name" {
+			itemName = opt.StringValue()
+		}
+	}
+
+	slog.Info("item command received", "item", itemName, "user", getUsername(i))
+
+	// Defer response immediately - Ollama calls can be slow
+	if err := deferResponse(s, i); err != nil {
+		slog.Error("failed to defer response", "error", err)
+		return
+	}
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load catalog: "+err.Error())
+		return
+	}
+
+	item, err := catalog.FindItem(itemName)
+	if err != nil {
+		var ambiguous *shop.AmbiguousItemError
+		if errors.As(err, &ambiguous) {
+			var buttons []discordgo.MessageComponent
+			for _, candidate := range ambiguous.Candidates {
+				buttons = append(buttons, discordgo.Button{
+					Label:    truncateLabel(candidate.Name, 80),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "item:" + candidate.Name,
+				})
+			}
+			content := fmt.Sprintf("*Grash squints.* \"'%s'? Be specific. Which one?\"", itemName)
+			editDeferredWithComponents(s, i, content, []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: buttons},
+			})
+			return
+		}
+		editDeferredResponse(s, i, fmt.Sprintf("Error: Item '%s' not found. Try /shop to see available items.", itemName))
+		return
+	}
+
+	showItemDetails(s, i, item)
+}
+
+// handleItemPick processes a button click from the /item disambiguation prompt ("item:<name>")
+func handleItemPick(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	_, itemName, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+	slog.Info("item pick received", "item", itemName, "user", getUsername(i))
+
+	if err := deferComponentUpdate(s, i); err != nil {
+		return
+	}
+
+	catalog, err := shop.LoadCatalog()
+	if err != nil {
+		editDeferredWithComponents(s, i, "Error: Failed to load catalog: "+err.Error(), []discordgo.MessageComponent{})
+		return
+	}
+
+	item, err := catalog.FindItem(itemName)
+	if err != nil {
+		editDeferredWithComponents(s, i, fmt.Sprintf("Error: Item '%s' is no longer available.", itemName), []discordgo.MessageComponent{})
+		return
+	}
+
+	showItemDetails(s, i, item)
+}
+
+// showItemDetails edits the deferred response with the item's full stats and,
+// if the caller has a character, Grash's opinion of the item for them
+func showItemDetails(s *discordgo.Session, i *discordgo.InteractionCreate, item *shop.Item) {
+	var response string
+
+	charFile, _ := shop.GetCharacterForUser(getUsername(i))
+	char, _ := shop.LoadCharacter(charFile)
+	if char != nil {
+		prompt := fmt.Sprintf("[%s]: Give me your honest opinion of the %s (%s gp) for someone like me. About me: %s",
+			char.Name, item.Name, shop.FormatCost(item.Cost), char.FormatCharacterSummary())
+		conv.AddMessage("user", prompt)
+		slog.Info("sending to ollama", "prompt", prompt)
+		start := time.Now()
+		aiResponse, err := conv.SendToOllama()
+		slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+		if err == nil {
+			response = aiResponse
+		}
+	}
+
+	// Let them try the item's dice
+	components := []discordgo.MessageComponent{}
+	if expr, kind, ok := shop.ItemDice(*item); ok {
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    truncateLabel(fmt.Sprintf("🎲 Roll %s (%s)", kind, expr), 80),
+				Style:    discordgo.SecondaryButton,
+				CustomID: "roll:" + expr.String(),
+			},
+		}})
+	}
+
+	editDeferredEmbed(s, i, response, buildItemEmbed(*item), components)
+}
+
+// handleRoll processes the /roll command
+func handleRoll(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	expr := ""
+	mode := dice.Normal
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "dice":
+			expr = opt.StringValue()
+		case "mode":
+			if opt.StringValue() == "advantage" {
+				mode = dice.Advantage
+			} else {
+				mode = dice.Disadvantage
+			}
+		}
+	}
+
+	slog.Info("roll command received", "dice", expr, "mode", mode, "user", getUsername(i))
+
+	parsed, err := dice.Parse(expr)
+	if err != nil {
+		respondWithError(s, i, err.Error())
+		return
+	}
+	result := parsed.WithMode(mode).Roll()
+	respondWithMessage(s, i, fmt.Sprintf("🎲 **%s** rolls %s", rollerName(i), result))
+}
+
+// handleRollButton rolls an item's dice from the button on its details.
+// The custom ID carries the dice, "roll:<dice>"; the item is named by the embed the button sits under
+func handleRollButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	expr := strings.TrimPrefix(i.MessageComponentData().CustomID, "roll:")
+	itemName := "item"
+	if i.Message != nil && len(i.Message.Embeds) > 0 && i.Message.Embeds[0].Title != "" {
+		itemName = i.Message.Embeds[0].Title
+	}
+	slog.Info("roll button received", "dice", expr, "item", itemName, "user", getUsername(i))
+
+	result, err := dice.Roll(expr)
+	if err != nil {
+		respondWithError(s, i, err.Error())
+		return
+	}
+	respondWithMessage(s, i, fmt.Sprintf("🎲 **%s** rolls the %s: %s", rollerName(i), itemName, result))
+}
+
+// rollerName is the caller's character name, or their Discord username if they don't have one
+func rollerName(i *discordgo.InteractionCreate) string {
+	if name, err := shop.GetCharacterNameForUser(getUsername(i)); err == nil {
+		return name
+	}
+	return getUsername(i)
+}
+
+// handleUse processes the /use command: uses up a consumable, rolls its dice, and lets
+// Grash grumble about restocking. Custom ID on the reply: "buy:1:<item name>"
+func handleUse(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	itemName := i.ApplicationCommandData().Options[0].StringValue()
+	username := getUsername(i)
+
+	slog.Info("use command received", "item", itemName, "user", username)
+
+	// Defer response immediately - Ollama calls can be slow
+	if err := deferResponse(s, i); err != nil {
+		slog.Error("failed to defer response", "error", err)
+		return
+	}
+
+	charFile, err := shop.GetCharacterForUser(username)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: You don't have a character registered. Contact the GM.")
+		return
+	}
+	char, err := shop.LoadCharacter(charFile)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load character: "+err.Error())
+		return
+	}
+
+	result, err := shop.UseItem(charFile, itemName)
+	if err != nil {
+		var notConsumable *shop.NotConsumableError
+		if errors.As(err, &notConsumable) {
+			editDeferredResponse(s, i, fmt.Sprintf("*Grash raises an eyebrow.* \"%s doesn't get used up. Keep it.\"", notConsumable.Item))
+			return
+		}
+		editDeferredResponse(s, i, "Error: "+err.Error())
+		return
+	}
+	item := result.Item
+
+	// Grash reacts to what's left; the roll is already made
+	used := "I just used my " + item.Name
+	if result.Roll != nil {
+		used += fmt.Sprintf(" (rolled %d %s)", result.Roll.Total, result.Kind)
+	}
+	left := "That was my last one."
+	if result.Remaining > 0 {
+		left = fmt.Sprintf("I have %d left.", result.Remaining)
+	}
+	prompt := fmt.Sprintf("[%s]: %s. %s In a sentence or two, grumble about me needing to restock.", char.Name, used, left)
+	conv.AddMessage("user", prompt)
+	slog.Info("sending to ollama", "prompt", prompt)
+	start := time.Now()
+	aiResponse, err := conv.SendToOllama()
+	slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+
+	var response string
+	if err == nil && aiResponse != "" {
+		response = aiResponse + "\n\n"
+	}
+	response += fmt.Sprintf("🧪 **%s** uses **%s**\n", char.Name, item.Name)
+	if result.Roll != nil {
+		kind := strings.ToUpper(result.Kind[:1]) + result.Kind[1:]
+		response += fmt.Sprintf("🎲 **%s:** %s\n", kind, result.Roll)
+	}
+	response += fmt.Sprintf("• Left: %d", result.Remaining)
+
+	slog.Info("item used", "item", item.Name, "character", char.Name, "remaining", result.Remaining)
+
+	// Offer a restock when Grash sells the item
+	components := []discordgo.MessageComponent{}
+	if item.Cost > 0 && !item.SoldOut() {
+		components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    truncateLabel(fmt.Sprintf("Restock %s (%s gp)", item.Name, shop.FormatCost(item.Cost)), 80),
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("buy:1:%s", item.Name),
+				},
+			}},
+		}
+	}
+	editDeferredWithComponents(s, i, response, components)
+}
+
+// handleCompare processes the /compare command
+func handleCompare(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var names []string
+	verdict := false
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "first", "second", "third":
+			names = append(names, opt.StringValue())
+		case "verdict":
+			verdict = opt.BoolValue()
+		}
+	}
+
+	slog.Info("compare command received", "items", names, "verdict", verdict, "user", getUsername(i))
 
 	// Defer response immediately - Ollama calls can be slow
 	if err := deferResponse(s, i); err != nil {
@@ -45,126 +694,131 @@ func handleShop(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 	var items []shop.Item
-	var title string
-
-	if category == "specials" {
-		items = shop.GetSessionSpecials()
-		title = "Session Specials"
-	} else {
-		items = catalog.GetItemsByCategory(category)
-		if category == "all" {
-			title = "All Shop Items"
-		} else {
-			title = strings.Title(category)
+	for _, name := range names {
+		item, err := catalog.FindItem(name)
+		if err != nil {
+			var ambiguous *shop.AmbiguousItemError
+			if errors.As(err, &ambiguous) {
+				editDeferredResponse(s, i, fmt.Sprintf("Error: %s. Be more specific.", ambiguous.Error()))
+				return
+			}
+			editDeferredResponse(s, i, fmt.Sprintf("Error: Item '%s' not found. Try /shop to see available items.", name))
+			return
 		}
+		items = append(items, *item)
 	}
 
-	if len(items) == 0 {
-		editDeferredResponse(s, i, fmt.Sprintf("No items found in category: %s", category))
-		return
-	}
-
-	// Get character context for personalized recommendations
+	// Compare against what the character carries now, if we know them
+	var carried shop.CarriedGear
 	charFile, _ := shop.GetCharacterForUser(getUsername(i))
 	char, _ := shop.LoadCharacter(charFile)
-
-	// Build response with AI flavor if available
-	var response string
 	if char != nil {
-		// Send to Ollama for quartermaster flavor
-		prompt := fmt.Sprintf("[%s]: Show me %s items", char.Name, category)
+		carried = catalog.FindCarriedGear(char)
+	}
+
+	table := shop.FormatComparison(items, carried)
+	response := fmt.Sprintf("**Comparison**\n%s", table)
+
+	if verdict && char != nil {
+		itemNames := make([]string, len(items))
+		for j, item := range items {
+			itemNames[j] = item.Name
+		}
+		prompt := fmt.Sprintf("[%s]: Which should I take: %s? Here's how they stack up:\n%s\nAbout me: %s",
+			char.Name, strings.Join(itemNames, " or "), table, char.FormatCharacterSummary())
 		conv.AddMessage("user", prompt)
 		slog.Info("sending to ollama", "prompt", prompt)
 		start := time.Now()
 		aiResponse, err := conv.SendToOllama()
 		slog.Info("ollama response received", "duration", time.Since(start), "error", err)
-		if err == nil {
-			response = aiResponse + "\n\n"
+		if err == nil && aiResponse != "" {
+			response += "\n\n**Grash's Verdict:** " + aiResponse
 		}
 	}
 
-	response += fmt.Sprintf("**%s**\n\n%s", title, shop.FormatItemList(items))
-
-	slog.Info("sending shop response", "category", category, "item_count", len(items))
 	editDeferredResponse(s, i, response)
 }
 
-// handleBuy processes the /buy command
-func handleBuy(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	options := i.ApplicationCommandData().Options
-	itemName := ""
-	quantity := 1
-
-	for _, opt := range options {
+// handleRecommend suggests three items for the caller's character, with Buy buttons.
+// Buttons reuse the "buy:<qty>:<name>" custom ID handled by handleBuyPick.
+func handleRecommend(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	budget := 0.0
+	category := ""
+	for _, opt := range i.ApplicationCommandData().Options {
 		switch opt.Name {
-		case "item":
-			itemName = opt.StringValue()
-		case "quantity":
-			quantity = int(opt.IntValue())
+		case "budget":
+			budget = opt.FloatValue()
+		case "category":
+			category = opt.StringValue()
 		}
 	}
 
-	slog.Info("buy command received", "item", itemName, "quantity", quantity, "user", getUsername(i))
+	username := getUsername(i)
+	slog.Info("recommend command received", "budget", budget, "category", category, "user", username)
 
-	// Defer response immediately - Ollama calls can be slow
-	if err := deferResponse(s, i); err != nil {
+	// Only the caller sees their picks. Defer - the curator call is slow.
+	if err := deferEphemeralResponse(s, i); err != nil {
 		slog.Error("failed to defer response", "error", err)
 		return
 	}
 
-	// Get character for this user
-	charFile, err := shop.GetCharacterForUser(getUsername(i))
+	charFile, err := shop.GetCharacterForUser(username)
 	if err != nil {
 		editDeferredResponse(s, i, "Error: You don't have a character registered. Contact the GM.")
 		return
 	}
-
 	char, err := shop.LoadCharacter(charFile)
 	if err != nil {
 		editDeferredResponse(s, i, "Error: Failed to load character: "+err.Error())
 		return
 	}
 
-	// Find the item in catalog
-	catalog, err := shop.LoadCatalog()
+	picks, err := shop.RecommendForCharacter(char, budget, category)
 	if err != nil {
-		editDeferredResponse(s, i, "Error: Failed to load catalog: "+err.Error())
+		editDeferredResponse(s, i, "Error: "+err.Error())
 		return
 	}
-
-	item, err := catalog.FindItem(itemName)
-	if err != nil {
-		editDeferredResponse(s, i, fmt.Sprintf("Error: Item '%s' not found. Try /shop to see available items.", itemName))
+	if len(picks) == 0 {
+		editDeferredResponse(s, i, "*Grash flips through the ledger and shrugs.* \"Nothing on my shelves for you at that price.\"")
 		return
 	}
 
-	// Log purchase for each quantity
-	totalCost := int(item.Cost) * quantity
-	for j := 0; j < quantity; j++ {
-		if err := shop.AppendPurchase(charFile, *item, "Between sessions"); err != nil {
-			editDeferredResponse(s, i, "Error: Failed to record purchase: "+err.Error())
-			return
-		}
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Grash's Picks for %s", char.Name),
+		Color: mundaneColor,
+	}
+	if budget > 0 {
+		embed.Description = fmt.Sprintf("Up to %s gp each", shop.FormatCost(budget))
 	}
 
-	// Generate AI response for flavor
-	prompt := fmt.Sprintf("[%s]: I want to buy %d %s", char.Name, quantity, item.Name)
-	conv.AddMessage("user", prompt)
-	slog.Info("sending to ollama", "prompt", prompt)
-	start := time.Now()
-	aiResponse, err := conv.SendToOllama()
-	slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+	var buttons []discordgo.MessageComponent
+	for _, pick := range picks {
+		name := fmt.Sprintf("%s - %s gp", pick.Item.Name, shop.FormatCost(pick.Item.Cost))
+		if pick.Item.Rarity != "" {
+			name += fmt.Sprintf(" (%s)", pick.Item.Rarity)
+		}
+		value := pick.Reason
+		if details := shop.ItemDetails(pick.Item); details != "" && details != pick.Item.Description {
+			value += "\n*" + details + "*"
+		}
+		if value == "" {
+			value = "\u200b"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  truncateLabel(name, 256),
+			Value: truncateLabel(value, 1024),
+		})
 
-	var response string
-	if err == nil && aiResponse != "" {
-		response = aiResponse + "\n\n"
+		buttons = append(buttons, discordgo.Button{
+			Label:    truncateLabel(fmt.Sprintf("Buy %s (%s gp)", pick.Item.Name, shop.FormatCost(pick.Item.Cost)), 80),
+			Style:    discordgo.PrimaryButton,
+			CustomID: fmt.Sprintf("buy:1:%s", pick.Item.Name),
+		})
 	}
 
-	response += fmt.Sprintf("**Purchase Recorded!**\n• Item: %s (x%d)\n• Total: %d gp\n• Character: %s\n\n*Remember to deduct gold from your character sheet!*",
-		item.Name, quantity, totalCost, char.Name)
-
-	slog.Info("purchase recorded", "item", item.Name, "quantity", quantity, "character", char.Name)
-	editDeferredResponse(s, i, response)
+	editDeferredEmbed(s, i, "", embed, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: buttons},
+	})
 }
 
 // handleInventory processes the /inventory command
@@ -188,10 +842,10 @@ func handleInventory(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	response := char.FormatInventory()
 
-	if history != nil && len(history.Purchases) > 0 {
+	if history != nil && len(history.Pending()) > 0 {
 		response += "\n**Recent Purchases (pending session confirmation):**\n"
-		for _, p := range history.Purchases {
-			response += fmt.Sprintf("• %s (%d gp) - %s\n", p.Item, p.Price, p.Date)
+		for _, p := range history.Pending() {
+			response += fmt.Sprintf("• %s (%s gp) - %s\n", p.Item, shop.FormatCost(p.Price), p.Date)
 		}
 	}
 
@@ -226,39 +880,383 @@ func handleHistory(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		response += "No purchases yet. Use /shop to browse available items!"
 	} else {
 		for _, p := range history.Purchases {
-			response += fmt.Sprintf("• **%s** - %d gp (%s)\n", p.Item, p.Price, p.Date)
+			response += fmt.Sprintf("• **%s** - %s gp (%s)\n", p.Item, shop.FormatCost(p.Price), p.Date)
+			if len(p.Modifiers) > 0 {
+				response += fmt.Sprintf("  *List %s gp: %s*\n", shop.FormatCost(p.BasePrice), shop.FormatModifiers(p.Modifiers))
+			}
 		}
-		response += fmt.Sprintf("\n**Total Spent:** %d gp", history.GetTotalSpent())
+		response += fmt.Sprintf("\n**Total Spent:** %s gp", shop.FormatCost(history.GetTotalSpent()))
 	}
 
 	respondWithMessage(s, i, response)
 }
 
-// handleRefresh processes the /refresh command (GM only)
+// handleWishlist processes the /wishlist add|remove|list subcommands
+func handleWishlist(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	sub := i.ApplicationCommandData().Options[0]
+	itemName := ""
+	for _, opt := range sub.Options {
+		if opt.Name == "item" {
+			itemName = opt.StringValue()
+		}
+	}
+
+	slog.Info("wishlist command received", "action", sub.Name, "item", itemName, "user", getUsername(i))
+
+	charFile, err := shop.GetCharacterForUser(getUsername(i))
+	if err != nil {
+		respondWithError(s, i, "You don't have a character registered. Contact the GM.")
+		return
+	}
+
+	switch sub.Name {
+	case "add":
+		catalog, err := shop.LoadCatalog()
+		if err != nil {
+			respondWithError(s, i, "Failed to load catalog: "+err.Error())
+			return
+		}
+		item, err := catalog.FindItem(itemName)
+		if err != nil {
+			var ambiguous *shop.AmbiguousItemError
+			if errors.As(err, &ambiguous) {
+				respondWithError(s, i, ambiguous.Error()+". Be more specific.")
+				return
+			}
+			respondWithError(s, i, fmt.Sprintf("Item '%s' not found. Try /shop to see available items.", itemName))
+			return
+		}
+		if err := shop.AddToWishlist(charFile, getUserID(i), item.Name); err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("*Grash scribbles in the margin.* \"Fine. If a **%s** comes through, you'll hear about it.\"", item.Name))
+
+	case "remove":
+		if err := shop.RemoveFromWishlist(charFile, itemName); err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("Removed **%s** from your wishlist.", itemName))
+
+	case "list":
+		wishlist, err := shop.LoadWishlist(charFile)
+		if err != nil {
+			respondWithError(s, i, "Failed to load wishlist: "+err.Error())
+			return
+		}
+		respondWithMessage(s, i, "**Your Wishlist**\n\n"+wishlist.FormatWishlist())
+	}
+}
+
+// handleRefresh curates a draft specials lineup that only the GM can see until it's published
 func handleRefresh(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	username := getUsername(i)
 	slog.Info("refresh command received", "user", username)
 
 	// GM-only check
-	if username != "egotch" {
+	if !isGM(username) {
 		respondWithMessage(s, i, "Only the GM can refresh session specials.")
 		return
 	}
 
-	// Defer response — Ollama call is slow
-	if err := deferResponse(s, i); err != nil {
+	// Defer response — Ollama call is slow. The draft stays hidden from players.
+	if err := deferEphemeralResponse(s, i); err != nil {
 		slog.Error("failed to defer response", "error", err)
 		return
 	}
 
-	items, err := shop.RefreshSessionSpecials()
+	draft, err := shop.DraftSessionSpecials()
 	if err != nil {
 		editDeferredResponse(s, i, "Error refreshing specials: "+err.Error())
 		return
 	}
 
-	response := fmt.Sprintf("**Session Specials Refreshed!** (%d items)\n\n%s", len(items), shop.FormatItemList(items))
-	editDeferredResponse(s, i, response)
+	embed, components := buildDraftView(draft)
+	editDeferredEmbed(s, i, draftNotice, embed, components)
+}
+
+// handleGM processes the /gm command groups (GM only)
+func handleGM(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	username := getUsername(i)
+	group := i.ApplicationCommandData().Options[0]
+	sub := group.Options[0]
+	slog.Info("gm command received", "group", group.Name, "action", sub.Name, "user", username)
+
+	if !isGM(username) {
+		respondWithMessage(s, i, "Only the GM can use GM tools.")
+		return
+	}
+
+	switch group.Name {
+	case "holds":
+		handleGMHolds(s, i, sub)
+	case "specials":
+		handleGMSpecials(s, i, sub)
+	case "purchases":
+		handleGMPurchases(s, i, sub)
+	case "sales":
+		handleGMSales(s, i, sub)
+	case "reputation":
+		handleGMReputation(s, i, sub)
+	}
+}
+
+// handleGMSales starts, ends and lists sale events
+func handleGMSales(s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	switch sub.Name {
+	case "list":
+		sales, err := shop.ActiveSales()
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		if len(sales) == 0 {
+			respondWithMessage(s, i, "No sales are running.")
+			return
+		}
+		var sb strings.Builder
+		sb.WriteString("**Running Sales**\n\n")
+		for _, sale := range sales {
+			sb.WriteString(fmt.Sprintf("• **%s** - %s%% off %s", sale.Name, shop.FormatCost(sale.Percent), saleScope(sale)))
+			if until, err := time.Parse(time.RFC3339, sale.Until); err == nil {
+				sb.WriteString(fmt.Sprintf(" until %s", until.Local().Format("Mon Jan 2 15:04")))
+			}
+			sb.WriteString("\n")
+		}
+		respondWithMessage(s, i, sb.String())
+
+	case "start":
+		sale := shop.SaleEvent{}
+		for _, opt := range sub.Options {
+			switch opt.Name {
+			case "name":
+				sale.Name = opt.StringValue()
+			case "percent":
+				sale.Percent = opt.FloatValue()
+			case "category":
+				sale.Category = opt.StringValue()
+			case "item":
+				sale.Item = opt.StringValue()
+			case "days":
+				hours := time.Duration(opt.FloatValue() * 24 * float64(time.Hour))
+				sale.Until = time.Now().Add(hours).Format(time.RFC3339)
+			}
+		}
+		if err := shop.StartSale(sale); err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("**%s** is on: %s%% off %s.", sale.Name, shop.FormatCost(sale.Percent), saleScope(sale)))
+
+	case "end":
+		name := sub.Options[0].StringValue()
+		if err := shop.EndSale(name); err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("**%s** has ended.", name))
+	}
+}
+
+// saleScope describes what a sale covers, e.g. "Potions" or "everything"
+func saleScope(sale shop.SaleEvent) string {
+	switch {
+	case sale.Item != "":
+		return sale.Item
+	case sale.Category != "":
+		return shop.CategoryTitle(sale.Category)
+	default:
+		return "everything"
+	}
+}
+
+// handleGMReputation sets and lists each character's standing with Grash
+func handleGMReputation(s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	switch sub.Name {
+	case "list":
+		reputation, err := shop.ListReputation()
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		if len(reputation) == 0 {
+			respondWithMessage(s, i, "Grash has no strong feelings about anyone yet.")
+			return
+		}
+		var files []string
+		for charFile := range reputation {
+			files = append(files, charFile)
+		}
+		sort.Strings(files)
+
+		var sb strings.Builder
+		sb.WriteString("**Reputation with Grash**\n\n")
+		for _, charFile := range files {
+			sb.WriteString(fmt.Sprintf("• %s: %+d\n", charFile, reputation[charFile]))
+		}
+		respondWithMessage(s, i, sb.String())
+
+	case "set":
+		name := ""
+		value := 0
+		for _, opt := range sub.Options {
+			switch opt.Name {
+			case "character":
+				name = opt.StringValue()
+			case "value":
+				value = int(opt.IntValue())
+			}
+		}
+		charFile, err := shop.FindCharacterFile(name)
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		value, err = shop.SetReputation(charFile, value)
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("%s's reputation with Grash is now %+d.", name, value))
+	}
+}
+
+// handleGMPurchases lists pending purchases and moves them into character inventories
+func handleGMPurchases(s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	switch sub.Name {
+	case "pending":
+		var sb strings.Builder
+		sb.WriteString("**Pending Purchases**\n\n")
+		var files []string
+		for _, charFile := range shop.GetUserCharacterMap() {
+			files = append(files, charFile)
+		}
+		sort.Strings(files)
+
+		found := false
+		for _, charFile := range files {
+			history, err := shop.LoadHistory(charFile)
+			if err != nil {
+				slog.Warn("failed to load history", "character", charFile, "error", err)
+				continue
+			}
+			pending := history.Pending()
+			if len(pending) == 0 {
+				continue
+			}
+			found = true
+			name := charFile
+			if char, err := shop.LoadCharacter(charFile); err == nil {
+				name = char.Name
+			}
+			sb.WriteString(fmt.Sprintf("**%s**\n", name))
+			for _, p := range pending {
+				sb.WriteString(fmt.Sprintf("• %s (%s gp) - %s\n", p.Item, shop.FormatCost(p.Price), p.Date))
+			}
+		}
+		if !found {
+			respondWithMessage(s, i, "No purchases are waiting for approval.")
+			return
+		}
+		respondWithMessage(s, i, sb.String())
+
+	case "approve":
+		name := sub.Options[0].StringValue()
+		charFile, err := shop.FindCharacterFile(name)
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		added, err := shop.ApprovePurchases(charFile)
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		if len(added) == 0 {
+			respondWithMessage(s, i, fmt.Sprintf("%s has no pending purchases.", name))
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("Added to %s's inventory: %s", name, strings.Join(added, ", ")))
+	}
+}
+
+// handleGMSpecials lists archived specials lineups or scheduled refresh runs, or rolls back to a lineup
+func handleGMSpecials(s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	switch sub.Name {
+	case "runs":
+		runs, err := shop.LoadRefreshRuns()
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, "**Scheduled Refreshes**\n\n"+shop.FormatRefreshRuns(runs, 15))
+
+	case "history":
+		archive, err := shop.LoadSpecialsArchive()
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, "**Specials Archive**\n\n"+archive.FormatHistory(15))
+
+	case "rollback":
+		version := 0
+		for _, opt := range sub.Options {
+			if opt.Name == "version" {
+				version = int(opt.IntValue())
+			}
+		}
+		snapshot, err := shop.RollbackSpecials(version)
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("**Rolled back to specials v%d** (%d items)\n\n%s",
+			snapshot.Version, len(snapshot.Items), shop.FormatItemList(snapshot.Items)))
+	}
+}
+
+// handleGMHolds lists or releases holds on session specials
+func handleGMHolds(s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	switch sub.Name {
+	case "list":
+		held := shop.GetHeldSpecials()
+		if len(held) == 0 {
+			respondWithMessage(s, i, "No specials are on hold.")
+			return
+		}
+		var sb strings.Builder
+		sb.WriteString("**Specials on Hold**\n\n")
+		now := time.Now()
+		for _, item := range held {
+			sb.WriteString(fmt.Sprintf("• **%s** - %s\n", item.Name, item.HoldLabel(now)))
+		}
+		respondWithMessage(s, i, sb.String())
+
+	case "release":
+		itemName := ""
+		for _, opt := range sub.Options {
+			if opt.Name == "item" {
+				itemName = opt.StringValue()
+			}
+		}
+		released, err := shop.ReleaseHolds(itemName)
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		if len(released) == 0 {
+			respondWithMessage(s, i, "No specials were on hold.")
+			return
+		}
+		respondWithMessage(s, i, fmt.Sprintf("Released holds on: %s", strings.Join(released, ", ")))
+	}
+}
+
+// isGM reports whether a Discord username belongs to the GM
+func isGM(username string) bool {
+	return strings.EqualFold(username, config.GMUsername)
 }
 
 // deferResponse tells Discord we're working on it (gives us 15 min instead of 3 sec)
@@ -268,6 +1266,16 @@ func deferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	})
 }
 
+// deferEphemeralResponse acknowledges an interaction with a deferred reply only the caller can see
+func deferEphemeralResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
 // respondWithMessage sends an interaction response (use for immediate responses)
 func respondWithMessage(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
 	// Discord has a 2000 character limit, truncate if needed
@@ -313,9 +1321,102 @@ func editDeferredResponse(s *discordgo.Session, i *discordgo.InteractionCreate,
 	}
 }
 
+// editDeferredWithComponents edits a deferred response and replaces its components.
+// Pass an empty slice to strip buttons from a message.
+func editDeferredWithComponents(s *discordgo.Session, i *discordgo.InteractionCreate, message string, components []discordgo.MessageComponent) {
+	if len(message) > 1900 {
+		message = message[:1900] + "\n\n*...response truncated*"
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &message,
+		Components: &components,
+	})
+	if err != nil {
+		slog.Error("failed to edit deferred response", "error", err)
+	}
+}
+
+// editDeferredEmbed edits a deferred response with content, a single embed and components
+func editDeferredEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, message string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	if len(message) > 1900 {
+		message = message[:1900] + "\n\n*...response truncated*"
+	}
+
+	embeds := []*discordgo.MessageEmbed{embed}
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &message,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		slog.Error("failed to edit deferred response", "error", err)
+	}
+}
+
+// deferComponentUpdate acknowledges a component click that will edit its message later
+func deferComponentUpdate(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		slog.Error("failed to defer component response", "error", err)
+	}
+	return err
+}
+
+// updateComponentMessage replaces a clicked component's message in place. A nil embed clears the embed.
+func updateComponentMessage(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	embeds := []*discordgo.MessageEmbed{}
+	if embed != nil {
+		embeds = append(embeds, embed)
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Embeds:     embeds,
+			Components: components,
+		},
+	})
+	if err != nil {
+		slog.Error("failed to update component message", "error", err)
+	}
+}
+
+// editComponentMessage replaces a clicked component's message after a deferred update.
+// A nil embed clears the embed.
+func editComponentMessage(s *discordgo.Session, i *discordgo.InteractionCreate, content string, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	embeds := []*discordgo.MessageEmbed{}
+	if embed != nil {
+		embeds = append(embeds, embed)
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		slog.Error("failed to edit component message", "error", err)
+	}
+}
+
+// truncateLabel shortens text to fit Discord's component label limits
+func truncateLabel(label string, limit int) string {
+	runes := []rune(label)
+	if len(runes) <= limit {
+		return label
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
 // splitMessage breaks a long message into chunks that fit within Discord's limit.
 // It splits on item boundaries (lines starting with "• ") so items stay whole.
-// Falls back to newline splitting if no item boundary is found.
+// Falls back to newline splitting if no item boundary is found. A code block cut in two
+// is closed at the end of one chunk and reopened at the start of the next, so tables
+// keep their monospace layout; the limit leaves room for the extra fences.
 func splitMessage(message string, limit int) []string {
 	if len(message) <= limit {
 		return []string{message}
@@ -342,14 +1443,19 @@ func splitMessage(message string, limit int) []string {
 			cut = strings.LastIndex(searchArea, "\n")
 		}
 
-		// Last resort: hard cut
-		if cut <= 0 {
+		// Last resort: hard cut (also when the only newline ends a reopened fence)
+		if cut <= 0 || (cut <= len("```") && strings.HasPrefix(message, "```\n")) {
 			cut = limit
 		}
 
-		chunks = append(chunks, strings.TrimRight(message[:cut], "\n"))
+		chunk := strings.TrimRight(message[:cut], "\n")
 		message = message[cut:]
 		message = strings.TrimPrefix(message, "\n")
+		if strings.Count(chunk, "```")%2 == 1 {
+			chunk += "\n```"
+			message = "```\n" + message
+		}
+		chunks = append(chunks, chunk)
 	}
 
 	return chunks
@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/shop"
+)
+
+// rotationCheckInterval is how often we look for a new rotation period
+const rotationCheckInterval = time.Hour
+
+// notifyWishlists tells players when items on their wishlist appear in a lineup.
+// Each player gets a DM; if that fails we ping them in the shop channel instead.
+// A match is only marked as notified once one of those goes through, so anyone we
+// couldn't reach is tried again on the next check.
+func notifyWishlists(s *discordgo.Session, items []shop.Item, lineupName, lineupKey string) {
+	matches, err := shop.FindWishlistMatches(items, lineupKey)
+	if err != nil {
+		slog.Error("failed to check wishlists", "error", err)
+		return
+	}
+
+	for _, match := range matches {
+		message := fmt.Sprintf("*Grash slides a requisition form across the desk.* \"That **%s** you keep asking about? It's in %s. %s gp. Don't make me hold it.\"",
+			match.Item.Name, lineupName, shop.FormatCost(match.Item.Cost))
+
+		if !sendWishlistNotice(s, match, message) {
+			continue
+		}
+		if err := shop.MarkWishlistNotified(match.Character, match.Item.Name, lineupKey); err != nil {
+			slog.Error("failed to mark wishlist notice", "character", match.Character, "item", match.Item.Name, "error", err)
+		}
+	}
+}
+
+// sendWishlistNotice DMs a player about a wishlist match, falling back to a mention in the
+// shop channel, and reports whether either went through
+func sendWishlistNotice(s *discordgo.Session, match shop.WishlistMatch, message string) bool {
+	if sendDM(s, match.DiscordUserID, message) {
+		slog.Info("wishlist DM sent", "character", match.Character, "item", match.Item.Name)
+		return true
+	}
+
+	if ShopChannelID == "" {
+		slog.Warn("no way to notify wishlist match", "character", match.Character, "item", match.Item.Name)
+		return false
+	}
+	mention := match.Character
+	if match.DiscordUserID != "" {
+		mention = fmt.Sprintf("<@%s>", match.DiscordUserID)
+	}
+	if _, err := s.ChannelMessageSend(ShopChannelID, mention+": "+message); err != nil {
+		slog.Error("failed to post wishlist notice", "error", err)
+		return false
+	}
+	return true
+}
+
+// sendDM sends a direct message to a user, reporting whether it went through
+func sendDM(s *discordgo.Session, userID, message string) bool {
+	if userID == "" {
+		return false
+	}
+	channel, err := s.UserChannelCreate(userID)
+	if err != nil {
+		slog.Warn("failed to open DM channel", "user", userID, "error", err)
+		return false
+	}
+	if _, err := s.ChannelMessageSend(channel.ID, message); err != nil {
+		slog.Warn("failed to send DM", "user", userID, "error", err)
+		return false
+	}
+	return true
+}
+
+// watchRotation checks the seeded rotation against wishlists at startup and then
+// periodically, so a new period's items reach the players who want them
+func watchRotation(s *discordgo.Session) {
+	ticker := time.NewTicker(rotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		period := shop.CurrentRotationPeriod()
+		items, err := shop.GetRotation(period)
+		if err != nil {
+			slog.Error("failed to load rotation for wishlist check", "error", err)
+		} else {
+			notifyWishlists(s, items, shop.CurrentRotationLabel(), "monthly:"+period)
+		}
+		<-ticker.C
+	}
+}
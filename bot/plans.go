@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/shop"
+)
+
+// defaultPlanWeight is the carry weight a plan fills when the player doesn't say, in lb
+const defaultPlanWeight = 50
+
+// handlePlan builds an optimized shopping list for a goal and budget, with Grash's take on it.
+// Custom IDs on the reply: "plan:accept:<plan id>", "plan:discard:<plan id>"
+func handlePlan(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	budget := 0.0
+	goal := ""
+	maxWeight := float64(defaultPlanWeight)
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "budget":
+			budget = opt.FloatValue()
+		case "goal":
+			goal = opt.StringValue()
+		case "max_weight":
+			maxWeight = opt.FloatValue()
+		}
+	}
+
+	username := getUsername(i)
+	slog.Info("plan command received", "budget", budget, "goal", goal, "max_weight", maxWeight, "user", username)
+
+	// Only the caller sees their list. Defer - Grash's explanation is slow.
+	if err := deferEphemeralResponse(s, i); err != nil {
+		slog.Error("failed to defer response", "error", err)
+		return
+	}
+
+	charFile, err := shop.GetCharacterForUser(username)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: You don't have a character registered. Contact the GM.")
+		return
+	}
+	char, err := shop.LoadCharacter(charFile)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: Failed to load character: "+err.Error())
+		return
+	}
+
+	plan, err := shop.BuildPlan(charFile, char, budget, maxWeight, goal)
+	if err != nil {
+		editDeferredResponse(s, i, "Error: "+err.Error())
+		return
+	}
+	if len(plan.Lines) == 0 {
+		editDeferredResponse(s, i, fmt.Sprintf("*Grash scratches her head.* \"Nothing on my shelves helps with '%s' for %s gp.\"",
+			goal, shop.FormatCost(budget)))
+		return
+	}
+	shop.StorePlan(plan)
+
+	// The list is fixed; Grash only explains it
+	var names []string
+	for _, line := range plan.Lines {
+		names = append(names, fmt.Sprintf("%d %s", line.Quantity, line.Item.Name))
+	}
+	prompt := fmt.Sprintf("[%s]: I've got %s gp to get ready for: %s. You've put together this list: %s. In a few sentences, tell me why.",
+		char.Name, shop.FormatCost(budget), goal, strings.Join(names, ", "))
+	conv.AddMessage("user", prompt)
+	slog.Info("sending to ollama", "prompt", prompt)
+	start := time.Now()
+	aiResponse, err := conv.SendToOllama()
+	slog.Info("ollama response received", "duration", time.Since(start), "error", err)
+
+	content := ""
+	if err == nil && aiResponse != "" {
+		content = aiResponse
+	}
+
+	embed, components := buildPlanView(plan)
+	editDeferredEmbed(s, i, content, embed, components)
+}
+
+// buildPlanView renders a shopping plan with its accept and discard buttons
+func buildPlanView(plan *shop.ShoppingPlan) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Title:       truncateLabel(fmt.Sprintf("Shopping List for %s: %s", plan.CharacterName, plan.Goal), 256),
+		Description: truncateLabel(plan.FormatLines(), 4096),
+		Color:       mundaneColor,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s of %s gp • %s of %s lb",
+				shop.FormatCost(plan.TotalCost), shop.FormatCost(plan.Budget),
+				shop.FormatCost(plan.TotalWeight), shop.FormatCost(plan.MaxWeight)),
+		},
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    fmt.Sprintf("Buy All (%s gp)", shop.FormatCost(plan.TotalCost)),
+				Style:    discordgo.SuccessButton,
+				CustomID: "plan:accept:" + plan.ID,
+			},
+			discordgo.Button{
+				Label:    "Discard",
+				Style:    discordgo.DangerButton,
+				CustomID: "plan:discard:" + plan.ID,
+			},
+		}},
+	}
+	return embed, components
+}
+
+// handlePlanAction processes the accept and discard buttons on a shopping plan
+func handlePlanAction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	parts := strings.SplitN(i.MessageComponentData().CustomID, ":", 3)
+	if len(parts) != 3 {
+		slog.Error("malformed plan button", "custom_id", i.MessageComponentData().CustomID)
+		return
+	}
+	action, planID := parts[1], parts[2]
+
+	slog.Info("plan action received", "action", action, "plan", planID, "user", getUsername(i))
+
+	if action == "discard" {
+		shop.DiscardPlan(planID)
+		updateComponentMessage(s, i, "*Grash tears up the list.* \"Suit yourself.\"", nil, []discordgo.MessageComponent{})
+		return
+	}
+
+	plan, err := shop.GetPlan(planID)
+	if err != nil {
+		updateComponentMessage(s, i, "Error: "+err.Error(), nil, []discordgo.MessageComponent{})
+		return
+	}
+	if err := deferComponentUpdate(s, i); err != nil {
+		return
+	}
+	quotes, err := shop.AcceptPlan(planID)
+	if err != nil {
+		editComponentMessage(s, i, purchaseErrorMessage(err), nil, []discordgo.MessageComponent{})
+		return
+	}
+
+	var lines []string
+	total := 0.0
+	for _, quote := range quotes {
+		line := fmt.Sprintf("• %s (x%d) - %s gp", quote.Item, quote.Quantity, shop.FormatCost(quote.Total))
+		if len(quote.Modifiers) > 0 {
+			line += fmt.Sprintf(" *(%s)*", shop.FormatModifiers(quote.Modifiers))
+		}
+		lines = append(lines, line)
+		total += quote.Total
+	}
+	content := fmt.Sprintf("**Purchase Recorded!**\n%s\n• Total: %s gp\n• Character: %s\n\n*Remember to deduct gold from your character sheet!*",
+		strings.Join(lines, "\n"), shop.FormatCost(total), plan.CharacterName)
+
+	slog.Info("plan purchased", "plan", planID, "items", len(plan.Lines), "character", plan.CharacterName)
+	editComponentMessage(s, i, content, nil, []discordgo.MessageComponent{})
+}
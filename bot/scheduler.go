@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/egotch/dnd-shopkeep/shop"
+)
+
+// startSpecialsScheduler starts the automatic specials refresh if SpecialsSchedule is set
+func startSpecialsScheduler(s *discordgo.Session) {
+	if SpecialsSchedule == "" {
+		return
+	}
+
+	schedule, err := shop.ParseSchedule(SpecialsSchedule)
+	if err != nil {
+		slog.Error("invalid specials schedule, automatic refresh disabled", "error", err)
+		return
+	}
+
+	slog.Info("specials scheduler started", "schedule", schedule.Expr)
+	go runSpecialsSchedule(s, schedule)
+}
+
+// runSpecialsSchedule sleeps until each scheduled time and refreshes the specials
+func runSpecialsSchedule(s *discordgo.Session, schedule *shop.Schedule) {
+	for {
+		next, ok := schedule.Next(time.Now())
+		if !ok {
+			slog.Error("specials schedule never fires, stopping scheduler", "schedule", schedule.Expr)
+			return
+		}
+		slog.Info("next scheduled specials refresh", "at", next)
+		time.Sleep(time.Until(next))
+
+		runScheduledRefresh(s)
+	}
+}
+
+// runScheduledRefresh refreshes the specials, records the outcome and posts the new lineup
+func runScheduledRefresh(s *discordgo.Session) {
+	run := shop.RefreshRun{Started: time.Now().Format(time.RFC3339)}
+	slog.Info("scheduled specials refresh starting")
+
+	items, err := shop.RefreshSessionSpecials()
+	run.Finished = time.Now().Format(time.RFC3339)
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+		slog.Error("scheduled specials refresh failed", "error", err)
+	} else {
+		run.Status = "ok"
+		run.ItemCount = len(items)
+		slog.Info("scheduled specials refresh finished", "item_count", len(items))
+	}
+
+	if err := shop.RecordRefreshRun(run); err != nil {
+		slog.Error("failed to record refresh run", "error", err)
+	}
+	if run.Status != "ok" {
+		return
+	}
+
+	if ShopChannelID != "" {
+		announceSpecials(s, ShopChannelID, items)
+	}
+
+	notifyWishlists(s, items, "Session Specials", "specials:"+run.Finished)
+}
+
+// announceSpecials posts a freshly published specials lineup to a channel
+func announceSpecials(s *discordgo.Session, channelID string, items []shop.Item) {
+	embed, components := buildShopPage("specials", shop.CategoryTitle("specials"), items, 0)
+	_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:    fmt.Sprintf("*Grash kicks open a fresh crate.* \"New stock. %d items. Don't all rush at once.\"", len(items)),
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	if err != nil {
+		slog.Error("failed to post specials", "error", err)
+	}
+}
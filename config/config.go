@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // DataPaths contains paths to data directories
 var DataPaths = struct {
 	Weapons         string
@@ -13,6 +15,15 @@ var DataPaths = struct {
 	MagicArmor      string
 	MagicPotions    string
 	WondrousItems   string
+	PriceBook       string
+	Wishlists       string
+	Stock           string
+	RefreshRuns     string
+	SpecialsArchive string
+	Embeddings      string
+	Bundles         string
+	PriceModifiers  string
+	Usage           string
 }{
 	Weapons:         "data/weapons.json",
 	Armor:           "data/armor.json",
@@ -25,7 +36,95 @@ var DataPaths = struct {
 	MagicArmor:      "data/magic_armor.json",
 	MagicPotions:    "data/magic_potions.json",
 	WondrousItems:   "data/wondrous_items.json",
+	PriceBook:       "data/price_book.json",
+	Wishlists:       "data/wishlists",
+	Stock:           "data/stock.json",
+	RefreshRuns:     "data/refresh_runs.json",
+	SpecialsArchive: "data/specials_archive.json",
+	Embeddings:      "data/embeddings.json",
+	Bundles:         "data/bundles.json",
+	PriceModifiers:  "data/price_modifiers.json",
+	Usage:           "data/usage",
+}
+
+// Rotation configures the seeded uncommon item rotation shown as /shop monthly
+var Rotation = struct {
+	Size   int      // Number of items in each rotation
+	Period string   // Rotation length: "month", "week" or "day"
+	Items  []string // Pool the rotation draws from; names must match the magic item data files exactly
+}{
+	Size:   5,
+	Period: "month",
+	Items: []string{
+		"Adamantine Weapon",
+		"Javelin of Lightning",
+		"Weapon of Warning",
+		"Adamantine Armor",
+		"Mithral Armor",
+		"Sentinel Shield",
+		"Potion of Fire Breath",
+		"Potion of Growth",
+		"Potion of Resistance",
+		"Bag of Holding",
+		"Bag of Tricks",
+		"Boots of Elvenkind",
+		"Boots of Striding and Springing",
+		"Bracers of Archery",
+		"Brooch of Shielding",
+		"Circlet of Blasting",
+		"Cloak of Elvenkind",
+		"Cloak of Protection",
+		"Driftglobe",
+		"Eyes of the Eagle",
+		"Gauntlets of Ogre Power",
+		"Gloves of Missile Snaring",
+		"Gloves of Thievery",
+		"Goggles of Night",
+		"Hat of Disguise",
+		"Headband of Intellect",
+		"Pearl of Power",
+		"Periapt of Wound Closure",
+		"Rope of Climbing",
+		"Sending Stones",
+		"Slippers of Spider Climbing",
+		"Stone of Good Luck (Luckstone)",
+		"Winged Boots",
+	},
+}
+
+// Curator controls how the specials curator narrows the item pool before prompting.
+// Each character gets their ShortlistSize closest items by embedding similarity.
+// If the embedding model isn't available, each character gets a random ShortlistSize
+// sample of the items they can pick instead.
+var Curator = struct {
+	EmbeddingModel string // Ollama embedding model, e.g. "nomic-embed-text"
+	ShortlistSize  int    // Items per character sent to the curator
+}{
+	EmbeddingModel: "nomic-embed-text",
+	ShortlistSize:  20,
+}
+
+// Pricing tunes the modifiers applied to prices at purchase time. Percentages are
+// percent off the list price.
+var Pricing = struct {
+	ReputationStep float64         // Percent off per point of reputation with Grash (negative marks prices up)
+	MaxReputation  int             // Reputation runs from -MaxReputation to +MaxReputation
+	BulkDiscounts  map[int]float64 // Minimum quantity -> percent off
+	MaxDiscount    float64         // Most all modifiers together can take off
+	HaggleDuration time.Duration   // How long a haggled price stays on offer
+}{
+	ReputationStep: 2,
+	MaxReputation:  5,
+	BulkDiscounts:  map[int]float64{5: 5, 10: 10},
+	MaxDiscount:    50,
+	HaggleDuration: 24 * time.Hour,
 }
 
+// GMUsername is the Discord username allowed to run GM-only commands
+var GMUsername = "egotch"
+
+// HoldDuration is how long a curated special stays reserved for the character it was picked for
+var HoldDuration = 72 * time.Hour
+
 // ShopkeeperName is the name of the quartermaster NPC
 var ShopkeeperName = "Grash Ironledger"
@@ -0,0 +1,407 @@
+// Package dice parses and rolls 5e dice notation like "1d8", "2d4+2", "4d6kh3" and "d20 adv".
+package dice
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Limits keep a single expression from rolling absurd amounts of dice
+const (
+	maxDice  = 100
+	maxSides = 1000
+	maxTerms = 20
+)
+
+// maxAverageOutcomes caps how many outcomes Average enumerates for a keep term
+const maxAverageOutcomes = 1_000_000
+
+// Mode is a roll with advantage, disadvantage, or neither
+type Mode int
+
+const (
+	Normal Mode = iota
+	Advantage
+	Disadvantage
+)
+
+// Term is one part of an expression: a group of dice, or a flat modifier when Sides is 0
+type Term struct {
+	Sign       int // +1 or -1
+	Count      int
+	Sides      int
+	Keep       int // Dice kept; 0 keeps them all
+	KeepLowest bool
+	Constant   int
+}
+
+// Expression is a parsed dice expression
+type Expression struct {
+	Terms []Term
+}
+
+// TermRoll is the dice rolled for one term and which of them counted
+type TermRoll struct {
+	Term  Term
+	Dice  []int
+	Kept  []bool
+	Total int // Signed total of the kept dice, or the constant
+}
+
+// Result is a rolled expression
+type Result struct {
+	Expression *Expression
+	Terms      []TermRoll
+	Total      int
+}
+
+// termRegex matches one term: "2d6", "d20", "d%", "4d6kh3", "2d20kl1" or a number
+var termRegex = regexp.MustCompile(`^(?:(\d*)d(\d+|%)(?:k([hl]?)(\d+))?|(\d+))$`)
+
+// findRegex finds the first dice expression in free text, e.g. "Regain 2d4 + 2 hit points"
+var findRegex = regexp.MustCompile(`(?i)\b\d*d\d+(?:\s*[+-]\s*\d+(?:d\d+)?)*`)
+
+// Parse reads a dice expression. Terms are joined with + or -, and a trailing "adv" or
+// "dis" (or "advantage"/"disadvantage") rolls the first d20 with that mode.
+func Parse(expr string) (*Expression, error) {
+	text := strings.ToLower(strings.TrimSpace(expr))
+	mode := Normal
+	for _, suffix := range []struct {
+		word string
+		mode Mode
+	}{
+		// Longer words first: "disadvantage" ends in "advantage"
+		{"disadvantage", Disadvantage}, {"advantage", Advantage}, {"dis", Disadvantage}, {"adv", Advantage},
+	} {
+		if strings.HasSuffix(text, suffix.word) {
+			text = strings.TrimSuffix(text, suffix.word)
+			mode = suffix.mode
+			break
+		}
+	}
+	text = strings.Join(strings.Fields(text), "")
+	if text == "" {
+		return nil, fmt.Errorf("empty dice expression")
+	}
+
+	e := &Expression{}
+	sign := 1
+	start := 0
+	if text[0] == '+' || text[0] == '-' {
+		if text[0] == '-' {
+			sign = -1
+		}
+		start = 1
+	}
+	for pos := start; pos <= len(text); pos++ {
+		if pos < len(text) && text[pos] != '+' && text[pos] != '-' {
+			continue
+		}
+		term, err := parseTerm(text[start:pos], sign)
+		if err != nil {
+			return nil, err
+		}
+		e.Terms = append(e.Terms, term)
+		if len(e.Terms) > maxTerms {
+			return nil, fmt.Errorf("too many terms in '%s' (max %d)", expr, maxTerms)
+		}
+		if pos < len(text) {
+			sign = 1
+			if text[pos] == '-' {
+				sign = -1
+			}
+		}
+		start = pos + 1
+	}
+
+	if mode != Normal {
+		e.applyMode(mode)
+	}
+	return e, nil
+}
+
+// parseTerm reads one term of an expression
+func parseTerm(text string, sign int) (Term, error) {
+	match := termRegex.FindStringSubmatch(text)
+	if match == nil {
+		return Term{}, fmt.Errorf("can't read '%s' as dice", text)
+	}
+	if match[5] != "" {
+		n, err := strconv.Atoi(match[5])
+		if err != nil {
+			return Term{}, fmt.Errorf("bad number '%s'", match[5])
+		}
+		return Term{Sign: sign, Constant: n}, nil
+	}
+
+	term := Term{Sign: sign, Count: 1}
+	if match[1] != "" {
+		term.Count, _ = strconv.Atoi(match[1])
+	}
+	if match[2] == "%" {
+		term.Sides = 100
+	} else {
+		term.Sides, _ = strconv.Atoi(match[2])
+	}
+	if term.Count < 1 || term.Count > maxDice {
+		return Term{}, fmt.Errorf("'%s' must roll between 1 and %d dice", text, maxDice)
+	}
+	if term.Sides < 2 || term.Sides > maxSides {
+		return Term{}, fmt.Errorf("'%s' needs between 2 and %d sides", text, maxSides)
+	}
+	if match[4] != "" {
+		term.Keep, _ = strconv.Atoi(match[4])
+		term.KeepLowest = match[3] == "l"
+		if term.Keep < 1 || term.Keep > term.Count {
+			return Term{}, fmt.Errorf("'%s' can't keep %d of %d dice", text, term.Keep, term.Count)
+		}
+	}
+	return term, nil
+}
+
+// applyMode turns the first single d20 (or, failing that, the first single die) into two
+// dice keeping the higher or lower
+func (e *Expression) applyMode(mode Mode) {
+	target := -1
+	for idx, term := range e.Terms {
+		if term.Sides == 0 || term.Count != 1 || term.Keep != 0 {
+			continue
+		}
+		if term.Sides == 20 {
+			target = idx
+			break
+		}
+		if target < 0 {
+			target = idx
+		}
+	}
+	if target < 0 {
+		return
+	}
+	e.Terms[target].Count = 2
+	e.Terms[target].Keep = 1
+	e.Terms[target].KeepLowest = mode == Disadvantage
+}
+
+// WithMode returns a copy of the expression rolled with advantage or disadvantage
+func (e *Expression) WithMode(mode Mode) *Expression {
+	c := &Expression{Terms: append([]Term(nil), e.Terms...)}
+	if mode != Normal {
+		c.applyMode(mode)
+	}
+	return c
+}
+
+// String writes the expression back in standard notation
+func (e *Expression) String() string {
+	var sb strings.Builder
+	for idx, term := range e.Terms {
+		switch {
+		case term.Sign < 0:
+			sb.WriteString("-")
+		case idx > 0:
+			sb.WriteString("+")
+		}
+		sb.WriteString(term.String())
+	}
+	return sb.String()
+}
+
+// String writes one term in standard notation, without its sign
+func (t Term) String() string {
+	if t.Sides == 0 {
+		return strconv.Itoa(t.Constant)
+	}
+	s := fmt.Sprintf("%dd%d", t.Count, t.Sides)
+	if t.Keep > 0 {
+		if t.KeepLowest {
+			s += fmt.Sprintf("kl%d", t.Keep)
+		} else {
+			s += fmt.Sprintf("kh%d", t.Keep)
+		}
+	}
+	return s
+}
+
+// Roll rolls the expression
+func (e *Expression) Roll() Result {
+	return e.roll(rand.IntN)
+}
+
+// roll rolls the expression with the given source of randomness (intN(n) returns 0..n-1)
+func (e *Expression) roll(intN func(int) int) Result {
+	result := Result{Expression: e}
+	for _, term := range e.Terms {
+		tr := TermRoll{Term: term}
+		if term.Sides == 0 {
+			tr.Total = term.Sign * term.Constant
+		} else {
+			tr.Dice = make([]int, term.Count)
+			for idx := range tr.Dice {
+				tr.Dice[idx] = intN(term.Sides) + 1
+			}
+			tr.Kept = keptDice(tr.Dice, term.Keep, term.KeepLowest)
+			for idx, die := range tr.Dice {
+				if tr.Kept[idx] {
+					tr.Total += term.Sign * die
+				}
+			}
+		}
+		result.Terms = append(result.Terms, tr)
+		result.Total += tr.Total
+	}
+	return result
+}
+
+// keptDice marks which dice count: all of them, or the keep highest (or lowest).
+// Ties keep the earlier die.
+func keptDice(dice []int, keep int, lowest bool) []bool {
+	kept := make([]bool, len(dice))
+	if keep == 0 || keep >= len(dice) {
+		for idx := range kept {
+			kept[idx] = true
+		}
+		return kept
+	}
+	for n := 0; n < keep; n++ {
+		best := -1
+		for idx, die := range dice {
+			if kept[idx] {
+				continue
+			}
+			if best < 0 || (!lowest && die > dice[best]) || (lowest && die < dice[best]) {
+				best = idx
+			}
+		}
+		kept[best] = true
+	}
+	return kept
+}
+
+// Average returns the expected total. Keep terms are averaged exactly by enumerating every
+// outcome when that's small enough, or else as if every kept die rolled its average.
+func (e *Expression) Average() float64 {
+	total := 0.0
+	for _, term := range e.Terms {
+		total += float64(term.Sign) * term.average()
+	}
+	return total
+}
+
+// average returns the expected value of one unsigned term
+func (t Term) average() float64 {
+	if t.Sides == 0 {
+		return float64(t.Constant)
+	}
+	if t.Keep == 0 || t.Keep >= t.Count {
+		return float64(t.Count) * float64(t.Sides+1) / 2
+	}
+
+	outcomes := 1
+	for n := 0; n < t.Count; n++ {
+		outcomes *= t.Sides
+		if outcomes > maxAverageOutcomes {
+			return float64(t.Keep) * float64(t.Sides+1) / 2
+		}
+	}
+
+	// Count through every combination of dice like an odometer
+	dice := make([]int, t.Count)
+	for idx := range dice {
+		dice[idx] = 1
+	}
+	sum := 0
+	for n := 0; n < outcomes; n++ {
+		kept := keptDice(dice, t.Keep, t.KeepLowest)
+		for idx, die := range dice {
+			if kept[idx] {
+				sum += die
+			}
+		}
+		for idx := range dice {
+			dice[idx]++
+			if dice[idx] <= t.Sides {
+				break
+			}
+			dice[idx] = 1
+		}
+	}
+	return float64(sum) / float64(outcomes)
+}
+
+// String shows the roll, e.g. "2d20kh1 (3, **17**) + 5 = 22". Kept dice are bold when
+// some are dropped.
+func (r Result) String() string {
+	var sb strings.Builder
+	for idx, tr := range r.Terms {
+		switch {
+		case tr.Term.Sign < 0:
+			sb.WriteString(" - ")
+		case idx > 0:
+			sb.WriteString(" + ")
+		}
+		if tr.Term.Sides == 0 {
+			sb.WriteString(strconv.Itoa(tr.Term.Constant))
+			continue
+		}
+
+		dropped := tr.Term.Keep > 0 && tr.Term.Keep < tr.Term.Count
+		var faces []string
+		for i, die := range tr.Dice {
+			face := strconv.Itoa(die)
+			if dropped && tr.Kept[i] {
+				face = "**" + face + "**"
+			}
+			faces = append(faces, face)
+		}
+		sb.WriteString(fmt.Sprintf("%s (%s)", tr.Term.String(), strings.Join(faces, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf(" = %d", r.Total))
+	return sb.String()
+}
+
+// Natural returns the kept face of a single d20 (including advantage/disadvantage pairs),
+// or 0 if the expression didn't roll exactly one kept d20
+func (r Result) Natural() int {
+	natural := 0
+	for _, tr := range r.Terms {
+		if tr.Term.Sides != 20 {
+			continue
+		}
+		for idx, die := range tr.Dice {
+			if tr.Kept[idx] {
+				if natural != 0 {
+					return 0
+				}
+				natural = die
+			}
+		}
+	}
+	return natural
+}
+
+// Roll parses and rolls an expression in one go
+func Roll(expr string) (Result, error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return Result{}, err
+	}
+	return e.Roll(), nil
+}
+
+// Find returns the first dice expression in free text, e.g. "2d4+2" from "Regain 2d4 + 2
+// hit points" or "1d8" from "1d8 Slashing"
+func Find(text string) (*Expression, bool) {
+	match := findRegex.FindString(text)
+	if match == "" {
+		return nil, false
+	}
+	e, err := Parse(match)
+	if err != nil {
+		return nil, false
+	}
+	return e, true
+}
@@ -0,0 +1,143 @@
+package dice
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // Expression.String() of the parsed result
+	}{
+		{"1d20", "1d20"},
+		{"d20+5", "1d20+5"},
+		{"2d6 + 1d4 - 1", "2d6+1d4-1"},
+		{"-2+d8", "-2+1d8"},
+		{"d%", "1d100"},
+		{"4d6kh3", "4d6kh3"},
+		{"4d6k3", "4d6kh3"},
+		{"2d20kl1", "2d20kl1"},
+		{"1d20+5 adv", "2d20kh1+5"},
+		{"1d20+5 advantage", "2d20kh1+5"},
+		{"1d20 dis", "2d20kl1"},
+		{"1d20 disadvantage", "2d20kl1"},
+		{"1d20DISADVANTAGE", "2d20kl1"},
+		{"1d6+1d20 adv", "1d6+2d20kh1"},
+		{"1d8 adv", "2d8kh1"},
+		{"2d6 adv", "2d6"},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if got := e.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // Substring of the error
+	}{
+		{"", "empty"},
+		{"adv", "empty"},
+		{"1d20dis+", "can't read"},
+		{"fireball", "can't read"},
+		{"0d6", "between 1 and 100 dice"},
+		{"101d6", "between 1 and 100 dice"},
+		{"1d1", "between 2 and 1000 sides"},
+		{"1d1001", "between 2 and 1000 sides"},
+		{"2d6kh3", "can't keep 3 of 2"},
+		{"2d6kl0", "can't keep 0 of 2"},
+		{strings.Repeat("1+", 20) + "1", "too many terms"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want error containing %q", tt.expr, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestRollKeep(t *testing.T) {
+	tests := []struct {
+		expr  string
+		faces []int // Faces rolled, in order
+		total int
+	}{
+		{"4d6kh3", []int{2, 6, 1, 4}, 12},
+		{"4d6kl1", []int{2, 6, 1, 4}, 1},
+		{"1d20 adv", []int{7, 15}, 15},
+		{"1d20 dis", []int{7, 15}, 7},
+		{"2d4+2", []int{3, 1}, 6},
+		{"d%-10", []int{42}, 32},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+		}
+		n := 0
+		result := e.roll(func(int) int {
+			face := tt.faces[n]
+			n++
+			return face - 1
+		})
+		if result.Total != tt.total {
+			t.Errorf("%s rolling %v = %d, want %d", tt.expr, tt.faces, result.Total, tt.total)
+		}
+	}
+}
+
+func TestAverage(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"2d4+2", 7},
+		{"1d20", 10.5},
+		{"1d20 adv", 13.825},
+		{"1d20 dis", 7.175},
+		{"4d6kh3", 12.244598765432098},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+		}
+		if got := e.Average(); got-tt.want > 1e-9 || tt.want-got > 1e-9 {
+			t.Errorf("Average(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"You regain 2d4 + 2 hit points when you drink this potion.", "2d4+2", true},
+		{"1d8 Slashing", "1d8", true},
+		{"A +1 weapon.", "", false},
+	}
+	for _, tt := range tests {
+		e, ok := Find(tt.text)
+		if ok != tt.ok {
+			t.Errorf("Find(%q) ok = %v, want %v", tt.text, ok, tt.ok)
+			continue
+		}
+		if ok && e.String() != tt.want {
+			t.Errorf("Find(%q) = %s, want %s", tt.text, e, tt.want)
+		}
+	}
+}
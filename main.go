@@ -10,7 +10,9 @@ import (
 func main() {
 	godotenv.Load()
 	bot.BotToken = os.Getenv("BOT_TOKEN")
-	bot.GuildID = os.Getenv("GUILD_ID") // Optional: set for faster dev registration
+	bot.GuildID = os.Getenv("GUILD_ID")                   // Optional: set for faster dev registration
+	bot.ShopChannelID = os.Getenv("SHOP_CHANNEL_ID")      // Optional: fallback channel for shop announcements
+	bot.SpecialsSchedule = os.Getenv("SPECIALS_SCHEDULE") // Optional: cron schedule for automatic specials refresh
 
 	bot.Run()
 }
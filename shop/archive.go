@@ -0,0 +1,262 @@
+package shop
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// SpecialsSnapshot is one generated specials lineup, kept so it can be reviewed or restored
+type SpecialsSnapshot struct {
+	Version    int                `json:"version"`
+	CreatedAt  string             `json:"created_at"`
+	Model      string             `json:"model"`
+	PromptHash string             `json:"prompt_hash"`
+	Selections []CuratorSelection `json:"selections"` // Curator picks with reasons
+	Items      []Item             `json:"items"`
+}
+
+// SpecialsArchive holds every specials lineup and which one is live
+type SpecialsArchive struct {
+	LiveVersion int                `json:"live_version"`
+	Snapshots   []SpecialsSnapshot `json:"snapshots"`
+}
+
+var archiveMu sync.Mutex
+
+// LoadSpecialsArchive loads the specials archive, returning an empty one if none exists yet
+func LoadSpecialsArchive() (*SpecialsArchive, error) {
+	data, err := os.ReadFile(config.DataPaths.SpecialsArchive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &SpecialsArchive{Snapshots: []SpecialsSnapshot{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read specials archive: %w", err)
+	}
+
+	var archive SpecialsArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("failed to parse specials archive: %w", err)
+	}
+	return &archive, nil
+}
+
+// saveSpecialsArchive writes the specials archive to file
+func saveSpecialsArchive(archive *SpecialsArchive) error {
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal specials archive: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.SpecialsArchive, data, 0644); err != nil {
+		return fmt.Errorf("failed to write specials archive: %w", err)
+	}
+	return nil
+}
+
+// HashPrompt returns a short stable hash of the prompts used to generate a lineup
+func HashPrompt(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// ArchiveSpecials records a new lineup as the next version and marks it live
+func ArchiveSpecials(model, promptHash string, selections []CuratorSelection, items []Item) (*SpecialsSnapshot, error) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	archive, err := LoadSpecialsArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	version := archive.nextVersion()
+	snapshot := SpecialsSnapshot{
+		Version:    version,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+		Model:      model,
+		PromptHash: promptHash,
+		Selections: selections,
+		Items:      items,
+	}
+	archive.Snapshots = append(archive.Snapshots, snapshot)
+	archive.LiveVersion = version
+
+	if err := saveSpecialsArchive(archive); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// replaceLiveSpecials writes a new lineup to the live specials file, first recording the
+// lineup it replaces in the archive
+func replaceLiveSpecials(items []Item) error {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	archive, err := LoadSpecialsArchive()
+	if err != nil {
+		return err
+	}
+
+	purchaseMu.Lock()
+	defer purchaseMu.Unlock()
+	if err := archive.recordLiveSpecials(); err != nil {
+		return err
+	}
+	if err := saveSpecialsArchive(archive); err != nil {
+		return err
+	}
+	return writeSessionSpecials(items)
+}
+
+// RollbackSpecials makes an archived lineup the live session specials again. Items sold
+// out or held while that lineup was live stay that way.
+func RollbackSpecials(version int) (*SpecialsSnapshot, error) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	archive, err := LoadSpecialsArchive()
+	if err != nil {
+		return nil, err
+	}
+	if archive.find(version) == nil {
+		return nil, fmt.Errorf("no specials version %d in the archive", version)
+	}
+
+	purchaseMu.Lock()
+	err = archive.recordLiveSpecials()
+	snapshot := archive.find(version)
+	if err == nil {
+		err = writeSessionSpecials(snapshot.Items)
+	}
+	purchaseMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	archive.LiveVersion = version
+	if err := saveSpecialsArchive(archive); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// recordLiveSpecials brings the archive up to date with the live specials file before it's
+// replaced. The live version picks up what's been sold or held since it was archived, and a
+// lineup that was never archived (such as the one live before archiving existed) is added
+// as a new version. Callers must hold archiveMu and purchaseMu.
+func (a *SpecialsArchive) recordLiveSpecials() error {
+	live, err := loadItemsFromFile(config.DataPaths.SessionSpecials, "specials")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to load session specials: %w", err)
+	}
+	if len(live) == 0 {
+		return nil
+	}
+
+	if snapshot := a.find(a.LiveVersion); snapshot != nil && sameItemNames(snapshot.Items, live) {
+		snapshot.Items = live
+		return nil
+	}
+
+	version := a.nextVersion()
+	a.Snapshots = append(a.Snapshots, SpecialsSnapshot{
+		Version:   version,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Model:     "unarchived",
+		Items:     live,
+	})
+	a.LiveVersion = version
+	return nil
+}
+
+// sameItemNames reports whether two lineups list the same items in the same order
+func sameItemNames(a, b []Item) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i].Name, b[i].Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextVersion returns the version number for the next snapshot
+func (a *SpecialsArchive) nextVersion() int {
+	if n := len(a.Snapshots); n > 0 {
+		return a.Snapshots[n-1].Version + 1
+	}
+	return 1
+}
+
+// PreviousSpecials returns the lineup archived before the live one, or nil if there isn't one
+func PreviousSpecials() (*SpecialsSnapshot, error) {
+	archive, err := LoadSpecialsArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *SpecialsSnapshot
+	for i := range archive.Snapshots {
+		snap := &archive.Snapshots[i]
+		if snap.Version < archive.LiveVersion && (previous == nil || snap.Version > previous.Version) {
+			previous = snap
+		}
+	}
+	return previous, nil
+}
+
+// find returns the snapshot with the given version, or nil
+func (a *SpecialsArchive) find(version int) *SpecialsSnapshot {
+	for i := range a.Snapshots {
+		if a.Snapshots[i].Version == version {
+			return &a.Snapshots[i]
+		}
+	}
+	return nil
+}
+
+// FormatHistory returns a formatted list of archived lineups, newest first
+func (a *SpecialsArchive) FormatHistory(limit int) string {
+	if len(a.Snapshots) == 0 {
+		return "No specials have been archived yet."
+	}
+
+	var sb strings.Builder
+	shown := 0
+	for i := len(a.Snapshots) - 1; i >= 0 && shown < limit; i-- {
+		snap := a.Snapshots[i]
+		created := snap.CreatedAt
+		if t, err := time.Parse(time.RFC3339, snap.CreatedAt); err == nil {
+			created = t.Local().Format("2006-01-02 15:04")
+		}
+
+		live := ""
+		if snap.Version == a.LiveVersion {
+			live = " **(live)**"
+		}
+		prompt := ""
+		if snap.PromptHash != "" {
+			prompt = fmt.Sprintf(" (prompt %s)", snap.PromptHash)
+		}
+		sb.WriteString(fmt.Sprintf("• **v%d**%s - %s, %d items, %s%s\n",
+			snap.Version, live, created, len(snap.Items), snap.Model, prompt))
+		shown++
+	}
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// Bundle is a GM-defined kit of catalog items sold together, optionally at a discount
+type Bundle struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Discount    float64      `json:"discount,omitempty"` // Percent off the items' total, e.g. 10
+	Items       []BundleItem `json:"items"`
+}
+
+// BundleItem is one catalog item in a bundle
+type BundleItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity,omitempty"` // Defaults to 1
+}
+
+// LoadBundles loads the GM's bundle definitions. A missing file means no bundles.
+func LoadBundles() ([]Bundle, error) {
+	data, err := os.ReadFile(config.DataPaths.Bundles)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read bundles: %w", err)
+	}
+
+	var file struct {
+		Bundles []Bundle `json:"bundles"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse bundles: %w", err)
+	}
+	return file.Bundles, nil
+}
+
+// bundleItems turns each bundle into a "kits" shop item priced from its contents.
+// Bundles naming an item that isn't in the catalog are skipped with a warning.
+func bundleItems(bundles []Bundle, items []Item) []Item {
+	byName := make(map[string]Item, len(items))
+	for _, item := range items {
+		// Mundane shelves load first, so a name on two shelves resolves to the plain item
+		if _, ok := byName[strings.ToLower(item.Name)]; !ok {
+			byName[strings.ToLower(item.Name)] = item
+		}
+	}
+
+	var kits []Item
+	for _, bundle := range bundles {
+		kit, err := resolveBundle(bundle, byName)
+		if err != nil {
+			slog.Warn("skipping bundle", "bundle", bundle.Name, "error", err)
+			continue
+		}
+		kits = append(kits, kit)
+	}
+	return kits
+}
+
+// resolveBundle builds the shop item for one bundle
+func resolveBundle(bundle Bundle, byName map[string]Item) (Item, error) {
+	if len(bundle.Items) == 0 {
+		return Item{}, fmt.Errorf("bundle has no items")
+	}
+
+	kit := Item{Name: bundle.Name, Category: "kits"}
+	total, weight := 0.0, 0.0
+	var parts []string
+	for _, entry := range bundle.Items {
+		item, ok := byName[strings.ToLower(entry.Name)]
+		if !ok {
+			return Item{}, fmt.Errorf("item '%s' not found in catalog", entry.Name)
+		}
+		quantity := max(entry.Quantity, 1)
+		kit.Contents = append(kit.Contents, CartLine{Item: item, Quantity: quantity})
+
+		total += item.Cost * float64(quantity)
+		weight += parseWeight(item.Weight) * float64(quantity)
+		if quantity > 1 {
+			parts = append(parts, fmt.Sprintf("%d× %s", quantity, item.Name))
+		} else {
+			parts = append(parts, item.Name)
+		}
+
+		// A kit runs out when any limited item in it does
+		if item.Stock != nil {
+			left := *item.Stock / quantity
+			if kit.Stock == nil || left < *kit.Stock {
+				kit.Stock = &left
+			}
+		}
+	}
+
+	discount := math.Min(math.Max(bundle.Discount, 0), 100)
+	kit.Cost = math.Round(total * (100 - discount) / 100)
+	if weight > 0 {
+		kit.Weight = strconv.FormatFloat(weight, 'f', -1, 64) + " lb."
+	}
+
+	description := "Contains " + strings.Join(parts, ", ")
+	if discount > 0 {
+		description += fmt.Sprintf(" (%s%% off)", strconv.FormatFloat(discount, 'f', -1, 64))
+	}
+	if bundle.Description != "" {
+		description = bundle.Description + " " + description
+	}
+	kit.Description = description
+	return kit, nil
+}
+
+// expandBundles replaces each kit in a cart with the items inside it, for stock and hold checks
+func expandBundles(lines []CartLine) []CartLine {
+	var expanded []CartLine
+	for _, line := range lines {
+		if len(line.Item.Contents) == 0 {
+			expanded = append(expanded, line)
+			continue
+		}
+		for _, content := range line.Item.Contents {
+			expanded = append(expanded, CartLine{Item: content.Item, Quantity: content.Quantity * line.Quantity})
+		}
+	}
+	return expanded
+}
+
+// inventoryEntries lists what a purchase adds to a character's inventory: one entry
+// per item, with kits opened up into their contents
+func inventoryEntries(item Item) []string {
+	if len(item.Contents) == 0 {
+		return nil
+	}
+	var entries []string
+	for _, content := range item.Contents {
+		for j := 0; j < content.Quantity; j++ {
+			entries = append(entries, content.Item.Name)
+		}
+	}
+	return entries
+}
@@ -3,8 +3,10 @@ package shop
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/egotch/dnd-shopkeep/config"
 )
@@ -26,6 +28,60 @@ type Item struct {
 	AC          string `json:"ac,omitempty"`
 	Strength    string `json:"strength,omitempty"`
 	Stealth     string `json:"stealth,omitempty"`
+	// Magic item fields
+	Type       string `json:"type,omitempty"`       // e.g. "Any Sword", "Shield"
+	Attunement string `json:"attunement,omitempty"` // e.g. "Requires Attunement"
+	// Limited stock - nil means unlimited
+	Stock *int `json:"stock,omitempty"`
+	// Reservation for the character a special was curated for
+	HeldFor   string `json:"held_for,omitempty"`   // Character display name
+	HoldUntil string `json:"hold_until,omitempty"` // RFC3339 expiry
+	// Used up by /use - unset leaves it to IsConsumable's category and name checks
+	Consumable *bool `json:"consumable,omitempty"`
+	// Kit contents, set when bundles are loaded
+	Contents []CartLine `json:"-"`
+}
+
+// HoldActive reports whether the item is reserved for someone right now
+func (i Item) HoldActive(now time.Time) bool {
+	if i.HeldFor == "" || i.HoldUntil == "" {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, i.HoldUntil)
+	if err != nil {
+		return false
+	}
+	return now.Before(until)
+}
+
+// HeldFromCharacter reports whether an active hold keeps this character from buying the item
+func (i Item) HeldFromCharacter(characterName string, now time.Time) bool {
+	return i.HoldActive(now) && !strings.EqualFold(i.HeldFor, characterName)
+}
+
+// HoldLabel returns "Held for X until Jan 2" for items on hold, or "" otherwise
+func (i Item) HoldLabel(now time.Time) string {
+	if !i.HoldActive(now) {
+		return ""
+	}
+	until, _ := time.Parse(time.RFC3339, i.HoldUntil)
+	return fmt.Sprintf("Held for %s until %s", i.HeldFor, until.Local().Format("Mon Jan 2 15:04"))
+}
+
+// SoldOut reports whether a limited-stock item has none left
+func (i Item) SoldOut() bool {
+	return i.Stock != nil && *i.Stock <= 0
+}
+
+// StockLabel returns "2 left" or "Sold out" for limited items, or "" if stock is unlimited
+func (i Item) StockLabel() string {
+	if i.Stock == nil {
+		return ""
+	}
+	if *i.Stock <= 0 {
+		return "Sold out"
+	}
+	return fmt.Sprintf("%d left", *i.Stock)
 }
 
 // Catalog represents the full shop inventory
@@ -51,6 +107,35 @@ var categoryMap = map[string]string{
 	config.DataPaths.Potions:         "potions",
 	config.DataPaths.AdventuringGear: "gear",
 	config.DataPaths.SessionSpecials: "specials",
+	config.DataPaths.MagicWeapons:    "magic_weapons",
+	config.DataPaths.MagicArmor:      "magic_armor",
+	config.DataPaths.MagicPotions:    "magic_potions",
+	config.DataPaths.WondrousItems:   "wondrous",
+}
+
+// categoryTitles maps category names to display titles
+var categoryTitles = map[string]string{
+	"all":           "All Shop Items",
+	"weapons":       "Weapons",
+	"armor":         "Armor",
+	"potions":       "Potions",
+	"gear":          "Adventuring Gear",
+	"specials":      "Session Specials",
+	"monthly":       "Rotation Specials",
+	"previous":      "Last Week's Specials",
+	"magic_weapons": "Magic Weapons",
+	"magic_armor":   "Magic Armor",
+	"magic_potions": "Magic Potions",
+	"wondrous":      "Wondrous Items",
+	"kits":          "Kits & Bundles",
+}
+
+// CategoryTitle returns the display title for a category
+func CategoryTitle(category string) string {
+	if title, ok := categoryTitles[strings.ToLower(category)]; ok {
+		return title
+	}
+	return category
 }
 
 // LoadCatalog loads the catalog from multiple category JSON files
@@ -73,6 +158,41 @@ func LoadCatalog() (*Catalog, error) {
 		catalog.Items = append(catalog.Items, items...)
 	}
 
+	// Load magic item files, priced by their fixed cost or a frozen roll
+	magicFiles := []string{
+		config.DataPaths.MagicWeapons,
+		config.DataPaths.MagicArmor,
+		config.DataPaths.MagicPotions,
+		config.DataPaths.WondrousItems,
+	}
+
+	for _, path := range magicFiles {
+		magicItems, err := loadMagicItemsFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		for _, magicItem := range magicItems {
+			item := magicItem.ToShopItem()
+			item.Category = categoryMap[path]
+			catalog.Items = append(catalog.Items, item)
+		}
+	}
+	if err := SavePriceBook(); err != nil {
+		slog.Warn("failed to save price book", "error", err)
+	}
+
+	// Limited catalog entries track what's left in the stock ledger
+	if err := applyStockLedger(catalog.Items); err != nil {
+		return nil, fmt.Errorf("failed to load stock: %w", err)
+	}
+
+	// GM-defined kits are priced from the catalog items they contain
+	if bundles, err := LoadBundles(); err != nil {
+		slog.Warn("failed to load bundles", "error", err)
+	} else {
+		catalog.Items = append(catalog.Items, bundleItems(bundles, catalog.Items)...)
+	}
+
 	// Load session specials
 	specials, err := loadItemsFromFile(config.DataPaths.SessionSpecials, "specials")
 	if err != nil {
@@ -103,6 +223,34 @@ func loadItemsFromFile(path string, category string) ([]Item, error) {
 	return file.Items, nil
 }
 
+// loadMagicItemsFromFile loads magic items from a single reference JSON file
+func loadMagicItemsFromFile(path string) ([]MagicItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var file struct {
+		Items []MagicItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	return file.Items, nil
+}
+
+// FilterShopItemsByLevel filters shop items to those a character of the given level can buy
+func FilterShopItemsByLevel(items []Item, level int) []Item {
+	var filtered []Item
+	for _, item := range items {
+		if CanBuyAtLevel(item, level) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
 // GetItemsByCategory returns items matching the given category
 func (c *Catalog) GetItemsByCategory(category string) []Item {
 	if category == "" || category == "all" {
@@ -119,35 +267,28 @@ func (c *Catalog) GetItemsByCategory(category string) []Item {
 	return filtered
 }
 
-// FindItem performs a fuzzy search for an item by name
+// FindItem performs a ranked fuzzy search for an item by name.
+// Returns an *AmbiguousItemError when the top matches are too close to call.
 func (c *Catalog) FindItem(name string) (*Item, error) {
-	name = strings.ToLower(name)
-
-	// First try exact match
-	for i, item := range c.Items {
-		if strings.ToLower(item.Name) == name {
-			return &c.Items[i], nil
-		}
+	results := c.SearchItems(name, 0)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("item '%s' not found in catalog", name)
 	}
 
-	// Then try contains match
-	for i, item := range c.Items {
-		if strings.Contains(strings.ToLower(item.Name), name) {
-			return &c.Items[i], nil
-		}
+	top := results[0]
+	if top.Score == 1.0 || len(results) == 1 || top.Score-results[1].Score > ambiguityMargin {
+		return &top.Item, nil
 	}
 
-	// Check session specials too
-	for i, item := range c.SessionSpecials.Items {
-		if strings.ToLower(item.Name) == name {
-			return &c.SessionSpecials.Items[i], nil
-		}
-		if strings.Contains(strings.ToLower(item.Name), name) {
-			return &c.SessionSpecials.Items[i], nil
+	// Several items are about as good as each other - let the player choose
+	var candidates []Item
+	for _, r := range results {
+		if top.Score-r.Score > ambiguityMargin*3 || len(candidates) == maxCandidates {
+			break
 		}
+		candidates = append(candidates, r.Item)
 	}
-
-	return nil, fmt.Errorf("item '%s' not found in catalog", name)
+	return nil, &AmbiguousItemError{Query: name, Candidates: candidates}
 }
 
 // GetCategories returns all unique categories in the catalog
@@ -164,6 +305,14 @@ func (c *Catalog) GetCategories() []string {
 	return categories
 }
 
+// FormatCost formats a price - shown as an int if whole number, otherwise with a decimal
+func FormatCost(cost float64) string {
+	if cost != float64(int(cost)) {
+		return fmt.Sprintf("%.1f", cost)
+	}
+	return fmt.Sprintf("%.0f", cost)
+}
+
 // FormatItemList returns a formatted string of items for display
 func FormatItemList(items []Item) string {
 	if len(items) == 0 {
@@ -172,40 +321,48 @@ func FormatItemList(items []Item) string {
 
 	var sb strings.Builder
 	for _, item := range items {
-		// Format cost - show as int if whole number, otherwise with decimal
-		costStr := fmt.Sprintf("%.0f", item.Cost)
-		if item.Cost != float64(int(item.Cost)) {
-			costStr = fmt.Sprintf("%.1f", item.Cost)
+		if stock := item.StockLabel(); stock != "" {
+			sb.WriteString(fmt.Sprintf("• **%s** - %s gp (%s)\n", item.Name, FormatCost(item.Cost), stock))
+		} else {
+			sb.WriteString(fmt.Sprintf("• **%s** - %s gp\n", item.Name, FormatCost(item.Cost)))
+		}
+
+		if details := ItemDetails(item); details != "" {
+			sb.WriteString(fmt.Sprintf("  *%s*\n", details))
 		}
-		sb.WriteString(fmt.Sprintf("• **%s** - %s gp\n", item.Name, costStr))
-
-		// Show details based on item type
-		switch item.Category {
-		case "weapons":
-			sb.WriteString(fmt.Sprintf("  *%s, %s*\n", item.Damage, item.Properties))
-		case "armor":
-			if item.AC != "" {
-				details := []string{fmt.Sprintf("AC %s", item.AC)}
-				if item.Strength != "" && item.Strength != "—" {
-					details = append(details, fmt.Sprintf("%s required", item.Strength))
-				}
-				if item.Stealth != "" && item.Stealth != "—" {
-					details = append(details, fmt.Sprintf("Stealth %s", item.Stealth))
-				}
-				if item.Weight != "" && item.Weight != "—" {
-					details = append(details, item.Weight)
-				}
-				sb.WriteString(fmt.Sprintf("  *%s*\n", strings.Join(details, ", ")))
-			}
-		case "gear":
-			if item.Weight != "" && item.Weight != "—" {
-				sb.WriteString(fmt.Sprintf("  *%s*\n", item.Weight))
-			}
-		default:
-			if item.Description != "" {
-				sb.WriteString(fmt.Sprintf("  *%s*\n", item.Description))
-			}
+		if hold := item.HoldLabel(time.Now()); hold != "" {
+			sb.WriteString(fmt.Sprintf("  *%s*\n", hold))
 		}
 	}
 	return sb.String()
 }
+
+// ItemDetails returns a one-line summary of an item's stats based on its type
+func ItemDetails(item Item) string {
+	switch item.Category {
+	case "weapons":
+		return fmt.Sprintf("%s, %s", item.Damage, item.Properties)
+	case "armor":
+		if item.AC == "" {
+			return ""
+		}
+		details := []string{fmt.Sprintf("AC %s", item.AC)}
+		if item.Strength != "" && item.Strength != "—" {
+			details = append(details, fmt.Sprintf("%s required", item.Strength))
+		}
+		if item.Stealth != "" && item.Stealth != "—" {
+			details = append(details, fmt.Sprintf("Stealth %s", item.Stealth))
+		}
+		if item.Weight != "" && item.Weight != "—" {
+			details = append(details, item.Weight)
+		}
+		return strings.Join(details, ", ")
+	case "gear":
+		if item.Weight != "" && item.Weight != "—" {
+			return item.Weight
+		}
+		return ""
+	default:
+		return item.Description
+	}
+}
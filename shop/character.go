@@ -17,6 +17,7 @@ type Character struct {
 	CurrentInventory []string `json:"current_inventory"`
 	BackstorySummary string   `json:"backstory_summary"`
 	Playstyle        string   `json:"playstyle"`
+	PersuasionBonus  int      `json:"persuasion_bonus,omitempty"` // Charisma (Persuasion) check bonus for /haggle
 }
 
 var charactersPath = "data/characters"
@@ -26,6 +27,9 @@ var userCharacterMap map[string]string
 var characterCache map[string]*Character
 var mapOnce sync.Once
 
+// characterCacheMu guards characterCache, which SaveCharacter updates while handlers read it
+var characterCacheMu sync.RWMutex
+
 // initCharacterMap scans the characters directory and builds the username mapping
 func initCharacterMap() {
 	userCharacterMap = make(map[string]string)
@@ -87,7 +91,10 @@ func LoadCharacter(name string) (*Character, error) {
 	ensureMapLoaded()
 
 	// Check cache first
-	if char, exists := characterCache[name]; exists {
+	characterCacheMu.RLock()
+	char, exists := characterCache[name]
+	characterCacheMu.RUnlock()
+	if exists {
 		return char, nil
 	}
 
@@ -95,6 +102,42 @@ func LoadCharacter(name string) (*Character, error) {
 	return loadCharacterFile(name)
 }
 
+// SaveCharacter writes a character profile to file and refreshes the cached copy
+func SaveCharacter(name string, char *Character) error {
+	ensureMapLoaded()
+
+	filename := filepath.Join(charactersPath, name+".json")
+	data, err := json.MarshalIndent(char, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal character '%s': %w", name, err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write character '%s': %w", name, err)
+	}
+
+	characterCacheMu.Lock()
+	if _, cached := characterCache[name]; cached {
+		characterCache[name] = char
+	}
+	characterCacheMu.Unlock()
+	return nil
+}
+
+// FindCharacterFile returns the file name of a character given its file name or display name
+func FindCharacterFile(name string) (string, error) {
+	ensureMapLoaded()
+
+	characterCacheMu.RLock()
+	defer characterCacheMu.RUnlock()
+	for file, char := range characterCache {
+		if strings.EqualFold(file, name) || strings.EqualFold(char.Name, name) {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("no character named '%s'", name)
+}
+
 // GetCharacterForUser returns the character filename for a Discord username
 func GetCharacterForUser(discordUsername string) (string, error) {
 	ensureMapLoaded()
@@ -126,6 +169,8 @@ func GetCharacterNameForUser(discordUsername string) (string, error) {
 func GetAllCharacters() []*Character {
 	ensureMapLoaded()
 
+	characterCacheMu.RLock()
+	defer characterCacheMu.RUnlock()
 	chars := make([]*Character, 0, len(characterCache))
 	for _, char := range characterCache {
 		chars = append(chars, char)
@@ -133,6 +178,24 @@ func GetAllCharacters() []*Character {
 	return chars
 }
 
+// PartyLevel returns the lowest character level in the party, which gates
+// what rarities the shop shows. Defaults to 1 with no characters loaded.
+func PartyLevel() int {
+	characters := GetAllCharacters()
+	if len(characters) == 0 {
+		return 1
+	}
+
+	minLevel := 20
+	for _, char := range characters {
+		level := ParseLevel(char.ClassLevel)
+		if level < minLevel {
+			minLevel = level
+		}
+	}
+	return minLevel
+}
+
 // GetUserCharacterMap returns a copy of the Discord handle to character file mapping
 func GetUserCharacterMap() map[string]string {
 	ensureMapLoaded()
@@ -0,0 +1,352 @@
+package shop
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ClassEntry is one class and its level from a class_level string
+type ClassEntry struct {
+	Class string
+	Level int
+}
+
+// proficiencies lists the armor and weapon training a class grants
+type proficiencies struct {
+	Armor   []string // "light", "medium", "heavy", "shield"
+	Simple  bool
+	Martial bool
+	// MartialProps limits martial weapons to those with one of these properties (Monk, Rogue)
+	MartialProps []string
+}
+
+// classProficiencies is the rules table of armor and weapon proficiencies per class (2024 PHB)
+var classProficiencies = map[string]proficiencies{
+	"artificer": {Armor: []string{"light", "medium", "shield"}, Simple: true},
+	"barbarian": {Armor: []string{"light", "medium", "shield"}, Simple: true, Martial: true},
+	"bard":      {Armor: []string{"light"}, Simple: true},
+	"cleric":    {Armor: []string{"light", "medium", "shield"}, Simple: true},
+	"druid":     {Armor: []string{"light", "medium", "shield"}, Simple: true},
+	"fighter":   {Armor: []string{"light", "medium", "heavy", "shield"}, Simple: true, Martial: true},
+	"monk":      {Simple: true, Martial: true, MartialProps: []string{"light"}},
+	"paladin":   {Armor: []string{"light", "medium", "heavy", "shield"}, Simple: true, Martial: true},
+	"ranger":    {Armor: []string{"light", "medium", "shield"}, Simple: true, Martial: true},
+	"rogue":     {Armor: []string{"light"}, Simple: true, Martial: true, MartialProps: []string{"finesse", "light"}},
+	"sorcerer":  {Simple: true},
+	"warlock":   {Armor: []string{"light"}, Simple: true},
+	"wizard":    {Simple: true},
+}
+
+// spellcasterClasses are the classes that satisfy "Requires Attunement by a Spellcaster"
+var spellcasterClasses = []string{"artificer", "bard", "cleric", "druid", "paladin", "ranger", "sorcerer", "warlock", "wizard"}
+
+// simpleWeapons are the PHB simple weapons; every other weapon in the catalog is martial
+var simpleWeapons = map[string]bool{
+	"club": true, "dagger": true, "greatclub": true, "handaxe": true, "javelin": true,
+	"light hammer": true, "mace": true, "quarterstaff": true, "sickle": true, "spear": true,
+	"dart": true, "light crossbow": true, "shortbow": true, "sling": true,
+}
+
+// martialWeaponProps lists the Finesse/Light martial weapons, for Monk and Rogue training
+var martialWeaponProps = map[string]string{
+	"rapier":        "finesse",
+	"scimitar":      "finesse, light",
+	"shortsword":    "finesse, light",
+	"whip":          "finesse",
+	"hand crossbow": "light",
+}
+
+// armorCategories maps mundane armor names to their category
+var armorCategories = map[string]string{
+	"padded armor": "light", "leather armor": "light", "studded leather armor": "light",
+	"hide armor": "medium", "chain shirt": "medium", "scale mail": "medium", "breastplate": "medium",
+	"half plate armor": "medium", "half-plate armor": "medium",
+	"ring mail": "heavy", "chain mail": "heavy", "splint armor": "heavy", "plate armor": "heavy",
+	"shield": "shield",
+}
+
+// classLevelRegex finds the level number in one part of a class_level string
+var classLevelRegex = regexp.MustCompile(`\d+`)
+
+// ParseClasses splits a class_level string into classes, e.g. "Fighter 3 / Wizard 2".
+// Parts with no recognised class are skipped.
+func ParseClasses(classLevel string) []ClassEntry {
+	var entries []ClassEntry
+	parts := strings.FieldsFunc(classLevel, func(r rune) bool {
+		return r == '/' || r == ',' || r == '|' || r == '+'
+	})
+	for _, part := range parts {
+		lower := strings.ToLower(part)
+		for class := range classProficiencies {
+			if !strings.Contains(lower, class) {
+				continue
+			}
+			level := 1
+			if match := classLevelRegex.FindString(part); match != "" {
+				if n, err := strconv.Atoi(match); err == nil {
+					level = n
+				}
+			}
+			entries = append(entries, ClassEntry{Class: class, Level: level})
+			break
+		}
+	}
+	return entries
+}
+
+// characterProficiencies merges the training of every class a character has.
+// Returns ok=false if no class could be recognised.
+func characterProficiencies(char *Character) (proficiencies, []string, bool) {
+	var merged proficiencies
+	var classes []string
+	armor := make(map[string]bool)
+	fullMartial := false
+	for _, entry := range ParseClasses(char.ClassLevel) {
+		prof := classProficiencies[entry.Class]
+		classes = append(classes, entry.Class)
+		for _, a := range prof.Armor {
+			armor[a] = true
+		}
+		merged.Simple = merged.Simple || prof.Simple
+		if prof.Martial {
+			merged.Martial = true
+			fullMartial = fullMartial || len(prof.MartialProps) == 0
+			merged.MartialProps = append(merged.MartialProps, prof.MartialProps...)
+		}
+	}
+	// Full martial training beats a property-limited one
+	if fullMartial {
+		merged.MartialProps = nil
+	}
+	for a := range armor {
+		merged.Armor = append(merged.Armor, a)
+	}
+	return merged, classes, len(classes) > 0
+}
+
+// ProficiencySummary describes a character's armor and weapon training, e.g. "light armor; simple weapons"
+func ProficiencySummary(char *Character) string {
+	prof, _, ok := characterProficiencies(char)
+	if !ok {
+		return ""
+	}
+
+	var armor []string
+	for _, a := range []string{"light", "medium", "heavy"} {
+		if prof.hasArmor(a) {
+			armor = append(armor, a)
+		}
+	}
+	armorText := "no armor"
+	if len(armor) > 0 {
+		armorText = strings.Join(armor, "/") + " armor"
+	}
+	if prof.hasArmor("shield") {
+		armorText += ", shields"
+	}
+
+	weaponText := "simple weapons"
+	if prof.Martial {
+		if len(prof.MartialProps) > 0 {
+			weaponText += ", martial weapons with " + strings.Join(prof.MartialProps, " or ")
+		} else {
+			weaponText += ", martial weapons"
+		}
+	}
+	return armorText + "; " + weaponText
+}
+
+// hasArmor reports whether the proficiencies include an armor category
+func (p proficiencies) hasArmor(category string) bool {
+	for _, a := range p.Armor {
+		if a == category {
+			return true
+		}
+	}
+	return false
+}
+
+// canWield reports whether the proficiencies cover a weapon
+func (p proficiencies) canWield(name, properties string) bool {
+	if simpleWeapons[strings.ToLower(name)] {
+		return p.Simple
+	}
+	if !p.Martial {
+		return false
+	}
+	if len(p.MartialProps) == 0 {
+		return true
+	}
+	props := strings.ToLower(properties)
+	for _, prop := range p.MartialProps {
+		if strings.Contains(props, prop) {
+			return true
+		}
+	}
+	return false
+}
+
+// armorOptions returns the armor categories an item could be, from the catalog category
+// or a magic item type like "Any Medium or Heavy" or "Chain Mail or Chain Shirt"
+func armorOptions(item Item) []string {
+	if item.Category == "armor" {
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(item.AC), "+"):
+			return []string{"shield"}
+		case strings.Contains(item.AC, "max 2"):
+			return []string{"medium"}
+		case strings.Contains(item.AC, "Dex"):
+			return []string{"light"}
+		default:
+			return []string{"heavy"}
+		}
+	}
+
+	lower := strings.ToLower(item.Type)
+	if lower == "" {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var options []string
+	add := func(category string) {
+		if !seen[category] {
+			seen[category] = true
+			options = append(options, category)
+		}
+	}
+	if strings.HasPrefix(lower, "any ") {
+		for _, category := range []string{"light", "medium", "heavy"} {
+			if strings.Contains(lower, category) {
+				add(category)
+			}
+		}
+	}
+	for name, category := range armorCategories {
+		if strings.Contains(lower, name) {
+			add(category)
+		}
+	}
+	return options
+}
+
+// weaponOptions returns the weapons (name and properties) an item could be.
+// ok=false means the item isn't a weapon, or any weapon will do ("Any Melee Weapon").
+func weaponOptions(item Item) (options [][2]string, ok bool) {
+	if item.Category == "weapons" {
+		return [][2]string{{item.Name, item.Properties}}, true
+	}
+
+	lower := strings.ToLower(item.Type)
+	if lower == "" {
+		return nil, false
+	}
+	if strings.Contains(lower, "any simple") {
+		options = append(options, [2]string{"club", ""})
+	}
+	if strings.Contains(lower, "martial") {
+		options = append(options, [2]string{"longsword", ""})
+	}
+	for _, part := range strings.FieldsFunc(lower, func(r rune) bool { return r == ',' }) {
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(part), "or "))
+		for _, option := range strings.Split(name, " or ") {
+			option = strings.TrimSpace(option)
+			if props, isMartial := martialWeaponProps[option]; isMartial {
+				options = append(options, [2]string{option, props})
+			} else if simpleWeapons[option] || knownMartialWeapon(option) {
+				options = append(options, [2]string{option, ""})
+			}
+		}
+	}
+	return options, len(options) > 0
+}
+
+// knownMartialWeapon reports whether a name is a martial weapon from the catalog tables
+func knownMartialWeapon(name string) bool {
+	switch name {
+	case "battleaxe", "flail", "glaive", "greataxe", "greatsword", "halberd", "lance", "longsword",
+		"maul", "morningstar", "pike", "rapier", "scimitar", "shortsword", "trident", "warhammer",
+		"war pick", "whip", "blowgun", "hand crossbow", "heavy crossbow", "longbow", "musket", "pistol":
+		return true
+	}
+	return false
+}
+
+// attunementClasses returns the classes an attunement requirement is limited to, e.g.
+// "Requires Attunement by a Cleric or Paladin". ok=false means it isn't limited by class.
+func attunementClasses(attunement string) (classes []string, ok bool) {
+	lower := strings.ToLower(attunement)
+	_, who, found := strings.Cut(lower, "attunement by ")
+	if !found {
+		return nil, false
+	}
+	if strings.Contains(who, "spellcaster") {
+		return spellcasterClasses, true
+	}
+	for class := range classProficiencies {
+		if strings.Contains(who, class) {
+			classes = append(classes, class)
+		}
+	}
+	return classes, len(classes) > 0
+}
+
+// ClassWarnings lists why a character can't make full use of an item: missing armor or
+// weapon proficiency, or an attunement limited to other classes. Characters whose class
+// can't be recognised get no warnings.
+func ClassWarnings(char *Character, item Item) []string {
+	prof, classes, ok := characterProficiencies(char)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	if options := armorOptions(item); len(options) > 0 {
+		usable := false
+		for _, category := range options {
+			if prof.hasArmor(category) {
+				usable = true
+				break
+			}
+		}
+		if !usable {
+			what := options[0] + " armor"
+			if options[0] == "shield" {
+				what = "shields"
+			}
+			warnings = append(warnings, fmt.Sprintf("%s isn't proficient with %s", char.Name, what))
+		}
+	} else if options, isWeapon := weaponOptions(item); isWeapon {
+		usable := false
+		for _, option := range options {
+			if prof.canWield(option[0], option[1]) {
+				usable = true
+				break
+			}
+		}
+		if !usable {
+			warnings = append(warnings, fmt.Sprintf("%s isn't proficient with %s", char.Name, item.Name))
+		}
+	}
+
+	if allowed, limited := attunementClasses(item.Attunement); limited {
+		match := false
+		for _, have := range classes {
+			for _, want := range allowed {
+				if have == want {
+					match = true
+				}
+			}
+		}
+		if !match {
+			warnings = append(warnings, fmt.Sprintf("%s can't attune to it (%s)", char.Name, item.Attunement))
+		}
+	}
+	return warnings
+}
+
+// ClassCanUse reports whether a character's class can use a magic item at all
+func ClassCanUse(char *Character, item MagicItem) bool {
+	return len(ClassWarnings(char, Item{Name: item.Name, Type: item.Type, Attunement: item.Attunement})) == 0
+}
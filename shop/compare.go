@@ -0,0 +1,241 @@
+package shop
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/egotch/dnd-shopkeep/dice"
+)
+
+// acRegex matches the leading number in an AC string like "11 + Dex modifier" or "+2"
+var acRegex = regexp.MustCompile(`^\s*(\+?)(\d+)`)
+
+// AverageDamage returns the average roll of the first dice expression in a damage string
+// (e.g. "1d8 Slashing" -> 4.5). Returns false if there are no dice.
+func AverageDamage(damage string) (float64, bool) {
+	expr, ok := dice.Find(damage)
+	if !ok {
+		return 0, false
+	}
+	return expr.Average(), true
+}
+
+// ItemDice returns the dice an item rolls and what for: "damage" for weapons, "healing" for
+// anything that restores hit points, or "effect" for other dice in its description
+func ItemDice(item Item) (expr *dice.Expression, kind string, ok bool) {
+	if expr, ok := dice.Find(item.Damage); ok {
+		return expr, "damage", true
+	}
+	expr, ok = dice.Find(item.Description)
+	if !ok {
+		return nil, "", false
+	}
+	lower := strings.ToLower(item.Description)
+	if strings.Contains(lower, "regain") && strings.Contains(lower, "hit points") {
+		return expr, "healing", true
+	}
+	return expr, "effect", true
+}
+
+// BaseAC returns the flat AC number from an armor's AC string. Shields ("+2") report
+// their bonus with isBonus set. Returns ok=false if the string has no number.
+func BaseAC(ac string) (value int, isBonus bool, ok bool) {
+	match := acRegex.FindStringSubmatch(ac)
+	if match == nil {
+		return 0, false, false
+	}
+	value, _ = strconv.Atoi(match[2])
+	return value, match[1] == "+", true
+}
+
+// CarriedGear is the best weapon, armor and shield a character already has,
+// resolved from their inventory against the catalog
+type CarriedGear struct {
+	Weapon *Item
+	Armor  *Item
+	Shield *Item
+}
+
+// carriedMatchScore is the minimum search score to treat an inventory entry as a catalog item
+const carriedMatchScore = 0.85
+
+// FindCarriedGear matches a character's inventory against the catalog to find
+// what they fight with now
+func (c *Catalog) FindCarriedGear(char *Character) CarriedGear {
+	var gear CarriedGear
+	bestDamage, bestAC := 0.0, 0
+
+	for _, entry := range char.CurrentInventory {
+		results := c.SearchItems(entry, 1)
+		if len(results) == 0 || results[0].Score < carriedMatchScore {
+			continue
+		}
+		item := results[0].Item
+
+		if avg, ok := AverageDamage(item.Damage); ok && avg > bestDamage {
+			bestDamage = avg
+			gear.Weapon = &item
+			continue
+		}
+		if value, isBonus, ok := BaseAC(item.AC); ok {
+			if isBonus {
+				gear.Shield = &item
+			} else if value > bestAC {
+				bestAC = value
+				gear.Armor = &item
+			}
+		}
+	}
+	return gear
+}
+
+// comparisonRow is one labelled row of a comparison table
+type comparisonRow struct {
+	label  string
+	values []string
+}
+
+// FormatComparison lays out items side by side in a monospace table, with damage and AC
+// deltas against what the character already carries
+func FormatComparison(items []Item, carried CarriedGear) string {
+	rows := []comparisonRow{
+		{label: "Cost"},
+		{label: "Damage"},
+		{label: "Avg Damage"},
+		{label: "Properties"},
+		{label: "Mastery"},
+		{label: "AC"},
+		{label: "AC Change"},
+		{label: "Strength"},
+		{label: "Stealth"},
+		{label: "Weight"},
+		{label: "Rarity"},
+	}
+
+	for _, item := range items {
+		rows[0].values = append(rows[0].values, FormatCost(item.Cost)+" gp")
+		rows[1].values = append(rows[1].values, item.Damage)
+		rows[2].values = append(rows[2].values, damageCell(item, carried.Weapon))
+		rows[3].values = append(rows[3].values, item.Properties)
+		rows[4].values = append(rows[4].values, item.Mastery)
+		rows[5].values = append(rows[5].values, item.AC)
+		rows[6].values = append(rows[6].values, acDeltaCell(item, carried))
+		rows[7].values = append(rows[7].values, item.Strength)
+		rows[8].values = append(rows[8].values, item.Stealth)
+		rows[9].values = append(rows[9].values, item.Weight)
+		rows[10].values = append(rows[10].values, normalizeRarity(item.Rarity))
+	}
+
+	// Drop rows where no item has anything to say
+	var kept []comparisonRow
+	for _, row := range rows {
+		for _, v := range row.values {
+			if v != "" && v != "—" {
+				kept = append(kept, row)
+				break
+			}
+		}
+	}
+
+	header := comparisonRow{label: ""}
+	for _, item := range items {
+		header.values = append(header.values, item.Name)
+	}
+	kept = append([]comparisonRow{header}, kept...)
+
+	const maxCell = 26
+	labelWidth := 0
+	widths := make([]int, len(items))
+	for _, row := range kept {
+		labelWidth = max(labelWidth, len(row.label))
+		for i, v := range row.values {
+			widths[i] = max(widths[i], min(len([]rune(v)), maxCell))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("```\n")
+	for _, row := range kept {
+		sb.WriteString(padRight(row.label, labelWidth))
+		for i, v := range row.values {
+			if v == "" {
+				v = "—"
+			}
+			sb.WriteString(" | ")
+			sb.WriteString(padRight(truncateRunes(v, maxCell), widths[i]))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("```")
+
+	var notes []string
+	if carried.Weapon != nil {
+		notes = append(notes, fmt.Sprintf("damage vs your %s", carried.Weapon.Name))
+	}
+	if carried.Armor != nil {
+		notes = append(notes, fmt.Sprintf("armor AC vs your %s", carried.Armor.Name))
+	}
+	if carried.Shield != nil {
+		notes = append(notes, fmt.Sprintf("shield AC vs your %s", carried.Shield.Name))
+	}
+	if len(notes) > 0 {
+		sb.WriteString(fmt.Sprintf("\n*Deltas: %s*", strings.Join(notes, ", ")))
+	}
+
+	return sb.String()
+}
+
+// damageCell shows average damage with a delta against the carried weapon
+func damageCell(item Item, carried *Item) string {
+	avg, ok := AverageDamage(item.Damage)
+	if !ok {
+		return ""
+	}
+	cell := fmt.Sprintf("%.1f", avg)
+	if carried != nil {
+		if carriedAvg, ok := AverageDamage(carried.Damage); ok {
+			cell += fmt.Sprintf(" (%+.1f)", avg-carriedAvg)
+		}
+	}
+	return cell
+}
+
+// acDeltaCell shows the AC difference against the carried armor or shield
+func acDeltaCell(item Item, carried CarriedGear) string {
+	value, isBonus, ok := BaseAC(item.AC)
+	if !ok {
+		return ""
+	}
+
+	compareTo := carried.Armor
+	if isBonus {
+		compareTo = carried.Shield
+	}
+	if compareTo == nil {
+		return ""
+	}
+	carriedValue, _, ok := BaseAC(compareTo.AC)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%+d", value-carriedValue)
+}
+
+// padRight pads a string with spaces to the given rune width
+func padRight(s string, width int) string {
+	if n := len([]rune(s)); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// truncateRunes shortens a string to limit runes, marking the cut with "…"
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
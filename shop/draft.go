@@ -0,0 +1,375 @@
+package shop
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// draftTTL is how long an unpublished specials draft is kept before it's thrown away
+const draftTTL = 24 * time.Hour
+
+// SpecialsDraft is a curated lineup the GM can review and adjust before players see it
+type SpecialsDraft struct {
+	ID         string
+	Created    time.Time
+	PromptHash string
+	Response   CuratorResponse
+	pool       []MagicItem  // Eligible items, for re-rolls and swaps
+	characters []*Character // Party the lineup was curated for
+}
+
+// DraftPick is one item in a draft, with its position in the lineup
+type DraftPick struct {
+	Index     int
+	Character string
+	Item      CuratorItem
+}
+
+var (
+	drafts   = make(map[string]*SpecialsDraft)
+	draftsMu sync.Mutex
+)
+
+// generateSpecialsDraft loads the party and item pool and asks the curator for a lineup
+func generateSpecialsDraft() (*SpecialsDraft, error) {
+	slog.Info("refreshing session specials via LLM curation")
+
+	// 1. Load all magic items
+	allItems, err := LoadAllMagicItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load magic items: %w", err)
+	}
+	slog.Info("loaded magic items", "count", len(allItems))
+
+	// 2. Load all characters
+	characters := GetAllCharacters()
+	if len(characters) == 0 {
+		return nil, fmt.Errorf("no characters found")
+	}
+	slog.Info("loaded characters", "count", len(characters))
+
+	// 3. Filter by rarity for each character's own level and class. The shared pool is everything
+	// the highest-level character could have; each pick is checked against its own character.
+	maxLevel := 1
+	for _, char := range characters {
+		level := ParseLevel(char.ClassLevel)
+		maxLevel = max(maxLevel, level)
+		slog.Info("filtered items by level", "character", char.Name, "level", level,
+			"eligible", len(FilterItemsByLevel(allItems, level)))
+	}
+	// Drop items nobody in the party could use (wrong class, no proficiency)
+	var filtered []MagicItem
+	for _, item := range FilterItemsByLevel(allItems, maxLevel) {
+		for _, char := range characters {
+			if canPick(char, item) {
+				filtered = append(filtered, item)
+				break
+			}
+		}
+	}
+	slog.Info("filtered items by class", "eligible", len(filtered))
+
+	// 4. Ask the curator
+	resp, promptHash, err := curateSpecials(characters, filtered)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpecialsDraft{
+		ID:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		Created:    time.Now(),
+		PromptHash: promptHash,
+		Response:   *resp,
+		pool:       filtered,
+		characters: characters,
+	}, nil
+}
+
+// DraftSessionSpecials curates a new lineup and keeps it as a draft without touching the live specials
+func DraftSessionSpecials() (*SpecialsDraft, error) {
+	draft, err := generateSpecialsDraft()
+	if err != nil {
+		return nil, err
+	}
+
+	draftsMu.Lock()
+	defer draftsMu.Unlock()
+	for id, d := range drafts {
+		if time.Since(d.Created) > draftTTL {
+			delete(drafts, id)
+		}
+	}
+	drafts[draft.ID] = draft
+	return draft.clone(), nil
+}
+
+// GetDraft returns a copy of a pending draft by ID. Re-rolls and swaps change the stored
+// draft under draftsMu, so callers get a copy they can read without the lock.
+func GetDraft(id string) (*SpecialsDraft, error) {
+	draftsMu.Lock()
+	defer draftsMu.Unlock()
+
+	draft, err := storedDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	return draft.clone(), nil
+}
+
+// storedDraft returns the stored draft by ID. Callers must hold draftsMu.
+func storedDraft(id string) (*SpecialsDraft, error) {
+	draft, ok := drafts[id]
+	if !ok || time.Since(draft.Created) > draftTTL {
+		return nil, fmt.Errorf("this draft has expired or was already published - run /refresh again")
+	}
+	return draft, nil
+}
+
+// updateDraft changes the stored draft under draftsMu and returns a copy of the result
+func updateDraft(id string, change func(draft *SpecialsDraft) error) (*SpecialsDraft, error) {
+	draftsMu.Lock()
+	defer draftsMu.Unlock()
+
+	draft, err := storedDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(draft); err != nil {
+		return nil, err
+	}
+	return draft.clone(), nil
+}
+
+// clone copies the draft's picks. The pool and characters are never changed, so they're shared.
+func (d *SpecialsDraft) clone() *SpecialsDraft {
+	c := *d
+	c.Response.Selections = make([]CuratorSelection, len(d.Response.Selections))
+	for i, sel := range d.Response.Selections {
+		c.Response.Selections[i] = CuratorSelection{Character: sel.Character, Items: append([]CuratorItem(nil), sel.Items...)}
+	}
+	return &c
+}
+
+// DiscardDraft throws away a pending draft
+func DiscardDraft(id string) {
+	draftsMu.Lock()
+	defer draftsMu.Unlock()
+	delete(drafts, id)
+}
+
+// PublishDraft writes a draft to the live specials and archives it
+func PublishDraft(id string) ([]Item, error) {
+	draft, err := GetDraft(id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := publishSpecials(draft)
+	if err != nil {
+		return nil, err
+	}
+	DiscardDraft(id)
+	return items, nil
+}
+
+// publishSpecials converts a lineup to shop items, writes it to the live specials and archives it.
+// Holds start counting from here, not from when the draft was generated.
+func publishSpecials(draft *SpecialsDraft) ([]Item, error) {
+	items := draft.Items()
+	slog.Info("generated session specials", "item_count", len(items))
+	if err := SavePriceBook(); err != nil {
+		slog.Warn("failed to save price book", "error", err)
+	}
+
+	// The lineup being replaced is archived first, so even the first refresh can be undone
+	if err := replaceLiveSpecials(items); err != nil {
+		return nil, err
+	}
+	slog.Info("session specials written", "path", config.DataPaths.SessionSpecials)
+
+	// Keep the lineup in the archive so a bad run can be rolled back
+	snapshot, err := ArchiveSpecials(curatorModel, draft.PromptHash, draft.Response.Selections, items)
+	if err != nil {
+		slog.Error("failed to archive session specials", "error", err)
+	} else {
+		slog.Info("session specials archived", "version", snapshot.Version)
+	}
+
+	return items, nil
+}
+
+// Items converts the draft's picks to shop items priced from the price book
+func (d *SpecialsDraft) Items() []Item {
+	return selectionsToItems(&d.Response, d.pool)
+}
+
+// Picks returns every item in the draft in lineup order
+func (d *SpecialsDraft) Picks() []DraftPick {
+	var picks []DraftPick
+	for _, sel := range d.Response.Selections {
+		for _, ci := range sel.Items {
+			picks = append(picks, DraftPick{Index: len(picks), Character: sel.Character, Item: ci})
+		}
+	}
+	return picks
+}
+
+// Characters returns the names of the characters the draft was curated for
+func (d *SpecialsDraft) Characters() []string {
+	names := make([]string, len(d.characters))
+	for i, char := range d.characters {
+		names[i] = char.Name
+	}
+	return names
+}
+
+// character returns the draft's character with the given name, or nil
+func (d *SpecialsDraft) character(name string) *Character {
+	for _, c := range d.characters {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// inLineup reports whether an item is already picked for anyone in the draft
+func (d *SpecialsDraft) inLineup(name string) bool {
+	for _, pick := range d.Picks() {
+		if strings.EqualFold(pick.Item.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RerollCharacter asks the curator for fresh picks for one character, keeping everyone else's.
+// The character is given by its position in Characters.
+func RerollCharacter(id string, characterIndex int) (*SpecialsDraft, error) {
+	draft, err := GetDraft(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if characterIndex < 0 || characterIndex >= len(draft.characters) {
+		return nil, fmt.Errorf("no character at position %d in this draft", characterIndex+1)
+	}
+	char := draft.characters[characterIndex]
+
+	// Leave out items other characters already have, plus this character's old picks
+	var pool []MagicItem
+	for _, item := range draft.pool {
+		if !draft.inLineup(item.Name) {
+			pool = append(pool, item)
+		}
+	}
+
+	resp, _, err := curateSpecials([]*Character{char}, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	var picks []CuratorItem
+	for _, sel := range resp.Selections {
+		picks = append(picks, sel.Items...)
+	}
+	if len(picks) == 0 {
+		return nil, fmt.Errorf("the curator didn't return any usable picks for %s", char.Name)
+	}
+
+	return updateDraft(id, func(draft *SpecialsDraft) error {
+		replaced := false
+		for i := range draft.Response.Selections {
+			if strings.EqualFold(draft.Response.Selections[i].Character, char.Name) {
+				draft.Response.Selections[i].Items = picks
+				replaced = true
+			}
+		}
+		if !replaced {
+			draft.Response.Selections = append(draft.Response.Selections, CuratorSelection{Character: char.Name, Items: picks})
+		}
+		return nil
+	})
+}
+
+// SwapCandidates returns pool items of the same rarity that could replace the pick at index.
+// Picks share a rarity with the item they replace, so they stay within the character's level.
+func (d *SpecialsDraft) SwapCandidates(index, limit int) []MagicItem {
+	picks := d.Picks()
+	if index < 0 || index >= len(picks) {
+		return nil
+	}
+
+	rarity := ""
+	for _, item := range d.pool {
+		if strings.EqualFold(item.Name, picks[index].Item.Name) {
+			rarity = normalizeRarity(item.Rarity)
+			break
+		}
+	}
+
+	var candidates []MagicItem
+	for _, item := range d.pool {
+		if len(candidates) == limit {
+			break
+		}
+		if normalizeRarity(item.Rarity) == rarity && !d.inLineup(item.Name) {
+			candidates = append(candidates, item)
+		}
+	}
+	return candidates
+}
+
+// SwapItem replaces the pick at index with another item from the draft's pool
+func SwapItem(id string, index int, newName string) (*SpecialsDraft, error) {
+	draft, err := GetDraft(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var replacement *MagicItem
+	for i := range draft.pool {
+		if strings.EqualFold(draft.pool[i].Name, newName) {
+			replacement = &draft.pool[i]
+			break
+		}
+	}
+	if replacement == nil {
+		return nil, fmt.Errorf("'%s' isn't eligible for this lineup", newName)
+	}
+
+	// Check against the stored draft, in case another click changed it since
+	return updateDraft(id, func(draft *SpecialsDraft) error {
+		if draft.inLineup(replacement.Name) {
+			return fmt.Errorf("'%s' is already in the lineup", replacement.Name)
+		}
+		picks := draft.Picks()
+		if index < 0 || index >= len(picks) {
+			return fmt.Errorf("no pick at position %d", index+1)
+		}
+		if char := draft.character(picks[index].Character); char != nil && !canPick(char, *replacement) {
+			return fmt.Errorf("%s can't use %s (level or class)", char.Name, replacement.Name)
+		}
+
+		n := 0
+		for s := range draft.Response.Selections {
+			for j := range draft.Response.Selections[s].Items {
+				if n == index {
+					draft.Response.Selections[s].Items[j] = CuratorItem{
+						Name:   replacement.Name,
+						Reason: "Swapped in by the GM",
+					}
+					return nil
+				}
+				n++
+			}
+		}
+		return fmt.Errorf("no pick at position %d", index+1)
+	})
+}
@@ -0,0 +1,189 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/ai"
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// embedBatchSize is how many texts go to Ollama in one embed call
+const embedBatchSize = 64
+
+// embedTimeout bounds a single embed call
+const embedTimeout = 2 * time.Minute
+
+// maxEmbedText caps how much of an item description is embedded
+const maxEmbedText = 1200
+
+// embeddingEntry is a cached vector plus a hash of the text it was made from
+type embeddingEntry struct {
+	TextHash string    `json:"text_hash"`
+	Vector   []float64 `json:"vector"`
+}
+
+// embeddingCache stores item vectors on disk so each item is only embedded once per model
+type embeddingCache struct {
+	Model string                    `json:"model"`
+	Items map[string]embeddingEntry `json:"items"`
+}
+
+var embeddingMu sync.Mutex
+
+// loadEmbeddingCache loads cached item vectors, starting fresh if the model changed
+func loadEmbeddingCache(model string) (*embeddingCache, error) {
+	fresh := &embeddingCache{Model: model, Items: make(map[string]embeddingEntry)}
+
+	data, err := os.ReadFile(config.DataPaths.Embeddings)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fresh, nil
+		}
+		return nil, fmt.Errorf("failed to read embeddings cache: %w", err)
+	}
+
+	var cache embeddingCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings cache: %w", err)
+	}
+	if cache.Model != model || cache.Items == nil {
+		return fresh, nil
+	}
+	return &cache, nil
+}
+
+// saveEmbeddingCache writes cached item vectors to file
+func saveEmbeddingCache(cache *embeddingCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embeddings cache: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.Embeddings, data, 0644); err != nil {
+		return fmt.Errorf("failed to write embeddings cache: %w", err)
+	}
+	return nil
+}
+
+// itemEmbeddingText is what gets embedded for a magic item
+func itemEmbeddingText(item MagicItem) string {
+	text := fmt.Sprintf("%s (%s %s). %s", item.Name, normalizeRarity(item.Rarity), item.Type, item.Description)
+	return truncateRunes(text, maxEmbedText)
+}
+
+// characterEmbeddingText is what gets embedded for a character
+func characterEmbeddingText(char *Character) string {
+	parts := []string{char.ClassLevel, char.BackstorySummary, char.Playstyle}
+	if prof := ProficiencySummary(char); prof != "" {
+		parts = append(parts, "Proficient with "+prof)
+	}
+	return strings.Join(parts, ". ")
+}
+
+// textHash is a short hash used to notice when an item's text has changed
+func textHash(text string) string {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// itemEmbeddings returns a vector for every item in the pool, embedding and caching any
+// that are new or whose text changed
+func itemEmbeddings(model string, pool []MagicItem) (map[string][]float64, error) {
+	embeddingMu.Lock()
+	defer embeddingMu.Unlock()
+
+	cache, err := loadEmbeddingCache(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MagicItem
+	var texts []string
+	for _, item := range pool {
+		text := itemEmbeddingText(item)
+		if entry, ok := cache.Items[item.Name]; ok && entry.TextHash == textHash(text) {
+			continue
+		}
+		missing = append(missing, item)
+		texts = append(texts, text)
+	}
+
+	if len(missing) > 0 {
+		slog.Info("embedding magic items", "model", model, "count", len(missing))
+		for start := 0; start < len(missing); start += embedBatchSize {
+			end := min(start+embedBatchSize, len(missing))
+			vectors, err := ai.Embed(model, texts[start:end], embedTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("failed to embed items: %w", err)
+			}
+			for i, vector := range vectors {
+				cache.Items[missing[start+i].Name] = embeddingEntry{
+					TextHash: textHash(texts[start+i]),
+					Vector:   vector,
+				}
+			}
+		}
+		if err := saveEmbeddingCache(cache); err != nil {
+			slog.Warn("failed to save embeddings cache", "error", err)
+		}
+	}
+
+	vectors := make(map[string][]float64, len(pool))
+	for _, item := range pool {
+		vectors[item.Name] = cache.Items[item.Name].Vector
+	}
+	return vectors, nil
+}
+
+// shortlistItems picks the size items from the pool closest to each character's backstory
+// and playstyle, skipping items the character can't use. Returns shortlists keyed by
+// character name, or an error if embeddings aren't available.
+func shortlistItems(characters []*Character, pool []MagicItem, size int) (map[string][]MagicItem, error) {
+	model := config.Curator.EmbeddingModel
+	itemVectors, err := itemEmbeddings(model, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(characters))
+	for i, char := range characters {
+		texts[i] = characterEmbeddingText(char)
+	}
+	charVectors, err := ai.Embed(model, texts, embedTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed characters: %w", err)
+	}
+
+	type scored struct {
+		item  MagicItem
+		score float64
+	}
+	shortlists := make(map[string][]MagicItem, len(characters))
+	for i, char := range characters {
+		var ranked []scored
+		for _, item := range pool {
+			if canPick(char, item) {
+				ranked = append(ranked, scored{item, ai.CosineSimilarity(charVectors[i], itemVectors[item.Name])})
+			}
+		}
+		sort.SliceStable(ranked, func(a, b int) bool {
+			return ranked[a].score > ranked[b].score
+		})
+
+		for _, r := range ranked[:min(size, len(ranked))] {
+			shortlists[char.Name] = append(shortlists[char.Name], r.item)
+		}
+		slog.Info("shortlisted items", "character", char.Name, "eligible", len(ranked), "shortlist", len(shortlists[char.Name]))
+	}
+	return shortlists, nil
+}
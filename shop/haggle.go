@@ -0,0 +1,115 @@
+package shop
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/dice"
+)
+
+// haggleDCs is the Persuasion DC to talk Grash down, by rarity
+var haggleDCs = map[string]int{
+	"common":    12,
+	"uncommon":  15,
+	"rare":      18,
+	"very rare": 21,
+	"legendary": 24,
+	"artifact":  27,
+}
+
+// mundaneHaggleDC is the DC for items with no rarity
+const mundaneHaggleDC = 10
+
+// HaggleResult is the outcome of one Persuasion check against Grash
+type HaggleResult struct {
+	Item    Item
+	DC      int
+	Natural int // The d20 roll, or 0 if the player rolled for themselves
+	Bonus   int
+	Total   int
+	Success bool
+	Percent float64 // Percent off granted; negative is a markup
+	Deal    HaggleDeal
+	Quote   Quote // The character's price with the deal applied
+}
+
+// AlreadyHaggledError is returned when a character haggles for an item while their last
+// quote on it still stands
+type AlreadyHaggledError struct {
+	Deal HaggleDeal
+}
+
+func (e *AlreadyHaggledError) Error() string {
+	until := e.Deal.Until
+	if t, err := time.Parse(time.RFC3339, e.Deal.Until); err == nil {
+		until = t.Local().Format("Mon Jan 2 15:04")
+	}
+	return fmt.Sprintf("you already haggled for '%s' - that offer stands until %s", e.Deal.Item, until)
+}
+
+// HaggleDC returns the Persuasion DC to haggle for an item
+func HaggleDC(item Item) int {
+	if dc, ok := haggleDCs[strings.ToLower(normalizeRarity(item.Rarity))]; ok {
+		return dc
+	}
+	return mundaneHaggleDC
+}
+
+// haggleOutcome turns a check into percent off. Meeting the DC is 10% off, beating it by 5
+// or more is 15%, and a natural 20 is 20%. A natural 1 insults Grash into a 10% markup.
+func haggleOutcome(natural, total, dc int) (bool, float64) {
+	switch {
+	case natural == 20:
+		return true, 20
+	case natural == 1:
+		return false, -10
+	case total >= dc+5:
+		return true, 15
+	case total >= dc:
+		return true, 10
+	default:
+		return false, 0
+	}
+}
+
+// Haggle makes a Persuasion check against Grash for an item the character's level allows. Pass the player's own check
+// total as playerTotal, or 0 to roll a d20 plus the character's persuasion_bonus. Win or
+// lose, the resulting price is a personal quote that /buy honours until it expires, and the
+// character can't haggle for the item again until then.
+func Haggle(characterFile string, char *Character, item Item, playerTotal int) (*HaggleResult, error) {
+	if err := checkLevel(item, ParseLevel(char.ClassLevel)); err != nil {
+		return nil, err
+	}
+	existing, err := GetHaggleDeal(characterFile, item.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, &AlreadyHaggledError{Deal: *existing}
+	}
+
+	result := &HaggleResult{Item: item, DC: HaggleDC(item)}
+	if playerTotal > 0 {
+		result.Total = playerTotal
+	} else {
+		roll, err := dice.Roll("1d20")
+		if err != nil {
+			return nil, err
+		}
+		result.Natural = roll.Total
+		result.Bonus = char.PersuasionBonus
+		result.Total = result.Natural + result.Bonus
+	}
+	result.Success, result.Percent = haggleOutcome(result.Natural, result.Total, result.DC)
+
+	result.Deal, err = GrantHaggleDeal(characterFile, item.Name, result.Percent)
+	if err != nil {
+		return nil, err
+	}
+	result.Quote, err = QuotePrice(characterFile, item, 1)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -3,18 +3,36 @@ package shop
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
 // Purchase represents a single purchase record
 type Purchase struct {
-	Date    string `json:"date"`
-	Item    string `json:"item"`
-	Price   int    `json:"price"`
-	Session string `json:"session"`
+	Date      string          `json:"date"`
+	Item      string          `json:"item"`
+	Price     float64         `json:"price"`                // What was paid, after modifiers
+	BasePrice float64         `json:"base_price,omitempty"` // List price, when modifiers changed it
+	Modifiers []PriceModifier `json:"modifiers,omitempty"`
+	Session   string          `json:"session"`
+	Contents  []string        `json:"contents,omitempty"` // Inventory entries a kit opens into
+	Approved  bool            `json:"approved"`           // Added to the character's inventory by the GM
+}
+
+// UnmarshalJSON reads a purchase. Records from before GM approval have no "approved" key;
+// the GM already copied those to character sheets by hand, so they count as approved.
+func (p *Purchase) UnmarshalJSON(data []byte) error {
+	type purchase Purchase
+	raw := purchase{Approved: true}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = Purchase(raw)
+	return nil
 }
 
 // PurchaseHistory represents a character's purchase log
@@ -73,7 +91,7 @@ func AppendPurchase(characterFile string, item Item, session string) error {
 	purchase := Purchase{
 		Date:    time.Now().Format("2006-01-02"),
 		Item:    item.Name,
-		Price:   int(item.Cost),
+		Price:   item.Cost,
 		Session: session,
 	}
 
@@ -81,6 +99,171 @@ func AppendPurchase(characterFile string, item Item, session string) error {
 	return SaveHistory(history)
 }
 
+// purchaseMu serializes purchases so stock checks and decrements can't interleave
+var purchaseMu sync.Mutex
+
+// CartLine is one item and how many of it to buy
+type CartLine struct {
+	Item     Item
+	Quantity int
+}
+
+// PurchaseItem records a purchase of quantity items as one transaction: level and holds are
+// checked, limited stock is decremented, then the purchases are appended to the character's
+// history. Returns the price paid, a *LevelTooLowError if the item's rarity is above the
+// character's level, a *HeldItemError if the item is reserved for someone else, or a
+// *SoldOutError if there isn't enough stock.
+func PurchaseItem(characterFile string, item Item, quantity int, session string) (Quote, error) {
+	quotes, err := PurchaseItems(characterFile, []CartLine{{Item: item, Quantity: quantity}}, session)
+	if err != nil {
+		return Quote{}, err
+	}
+	return quotes[0], nil
+}
+
+// PurchaseItems records several purchases atomically: if any line is above the character's
+// level, held or sold out, nothing is bought and any stock already taken is put back. Kits are checked item by item
+// but recorded as one purchase each. Each line is priced by the pricing pipeline; the quotes
+// are returned in line order.
+func PurchaseItems(characterFile string, lines []CartLine, session string) ([]Quote, error) {
+	purchaseMu.Lock()
+	defer purchaseMu.Unlock()
+
+	char, err := LoadCharacter(characterFile)
+	if err != nil {
+		return nil, err
+	}
+	level := ParseLevel(char.ClassLevel)
+	stockLines := expandBundles(lines)
+	for _, line := range stockLines {
+		if err := checkLevel(line.Item, level); err != nil {
+			return nil, err
+		}
+		if err := checkHold(line.Item, char.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	quotes := make([]Quote, len(lines))
+	for idx, line := range lines {
+		quotes[idx] = quoteWith(mods, characterFile, line.Item, line.Quantity, now)
+	}
+
+	// Put the stock back so a failed line or write doesn't eat earlier items
+	var taken []CartLine
+	restore := func() {
+		for _, line := range taken {
+			if restoreErr := adjustStock(line.Item, line.Quantity); restoreErr != nil {
+				slog.Error("failed to restore stock after purchase error", "item", line.Item.Name, "error", restoreErr)
+			}
+		}
+	}
+
+	for _, line := range stockLines {
+		if err := adjustStock(line.Item, -line.Quantity); err != nil {
+			restore()
+			return nil, err
+		}
+		taken = append(taken, line)
+	}
+
+	history, err := LoadHistory(characterFile)
+	if err == nil {
+		for idx, line := range lines {
+			purchase := Purchase{
+				Date:      now.Format("2006-01-02"),
+				Item:      line.Item.Name,
+				Price:     quotes[idx].Unit,
+				Modifiers: quotes[idx].Modifiers,
+				Session:   session,
+				Contents:  inventoryEntries(line.Item),
+			}
+			if quotes[idx].Unit != quotes[idx].Base {
+				purchase.BasePrice = quotes[idx].Base
+			}
+			for j := 0; j < line.Quantity; j++ {
+				history.Purchases = append(history.Purchases, purchase)
+			}
+		}
+		err = SaveHistory(history)
+	}
+
+	if err != nil {
+		restore()
+		return nil, err
+	}
+
+	// A haggled price is good for one purchase
+	if err := useHaggleDeals(characterFile, quotes); err != nil {
+		slog.Warn("failed to use up haggle deals", "character", characterFile, "error", err)
+	}
+	return quotes, nil
+}
+
+// Pending returns the purchases the GM hasn't added to the character's inventory yet
+func (h *PurchaseHistory) Pending() []Purchase {
+	var pending []Purchase
+	for _, p := range h.Purchases {
+		if !p.Approved {
+			pending = append(pending, p)
+		}
+	}
+	return pending
+}
+
+// ApprovePurchases moves a character's pending purchases into their inventory, opening
+// kits into the items inside them. Returns the inventory entries added.
+func ApprovePurchases(characterFile string) ([]string, error) {
+	purchaseMu.Lock()
+	defer purchaseMu.Unlock()
+
+	history, err := LoadHistory(characterFile)
+	if err != nil {
+		return nil, err
+	}
+	char, err := loadCharacterFile(characterFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for idx := range history.Purchases {
+		p := &history.Purchases[idx]
+		if p.Approved {
+			continue
+		}
+		if len(p.Contents) > 0 {
+			added = append(added, p.Contents...)
+		} else {
+			added = append(added, p.Item)
+		}
+		p.Approved = true
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	original := char.CurrentInventory
+	char.CurrentInventory = append(append([]string{}, original...), added...)
+	if err := SaveCharacter(characterFile, char); err != nil {
+		return nil, err
+	}
+	if err := SaveHistory(history); err != nil {
+		// Don't leave the items in the inventory and still pending
+		char.CurrentInventory = original
+		if restoreErr := SaveCharacter(characterFile, char); restoreErr != nil {
+			slog.Error("failed to restore inventory after approval error", "character", characterFile, "error", restoreErr)
+		}
+		return nil, err
+	}
+	return added, nil
+}
+
 // FormatHistory returns a formatted string of purchase history
 func (h *PurchaseHistory) FormatHistory() string {
 	if len(h.Purchases) == 0 {
@@ -91,7 +274,7 @@ func (h *PurchaseHistory) FormatHistory() string {
 	sb.WriteString(fmt.Sprintf("**Purchase History for %s**\n\n", h.Character))
 
 	for _, p := range h.Purchases {
-		sb.WriteString(fmt.Sprintf("• **%s** - %d gp (%s)\n", p.Item, p.Price, p.Date))
+		sb.WriteString(fmt.Sprintf("• **%s** - %s gp (%s)\n", p.Item, FormatCost(p.Price), p.Date))
 		if p.Session != "" {
 			sb.WriteString(fmt.Sprintf("  *Session: %s*\n", p.Session))
 		}
@@ -100,8 +283,8 @@ func (h *PurchaseHistory) FormatHistory() string {
 }
 
 // GetTotalSpent returns the total gold spent by this character
-func (h *PurchaseHistory) GetTotalSpent() int {
-	total := 0
+func (h *PurchaseHistory) GetTotalSpent() float64 {
+	total := 0.0
 	for _, p := range h.Purchases {
 		total += p.Price
 	}
@@ -0,0 +1,280 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// SaleEvent is a GM-run sale: a percent off everything, one category, or one item until it ends
+type SaleEvent struct {
+	Name     string  `json:"name"`
+	Percent  float64 `json:"percent"`            // Percent off
+	Category string  `json:"category,omitempty"` // Limit to a catalog category
+	Item     string  `json:"item,omitempty"`     // Limit to one item by name
+	Until    string  `json:"until,omitempty"`    // RFC3339 end; empty runs until the GM ends it
+}
+
+// Active reports whether the sale is running
+func (e SaleEvent) Active(now time.Time) bool {
+	if e.Until == "" {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339, e.Until)
+	return err == nil && now.Before(until)
+}
+
+// Covers reports whether the sale applies to an item
+func (e SaleEvent) Covers(item Item) bool {
+	if e.Item != "" && !strings.EqualFold(e.Item, item.Name) {
+		return false
+	}
+	if e.Category != "" && !strings.EqualFold(e.Category, item.Category) {
+		return false
+	}
+	return true
+}
+
+// HaggleDeal is a personal price one character talked Grash into, good for one purchase
+type HaggleDeal struct {
+	Character string  `json:"character"` // Character file name
+	Item      string  `json:"item"`
+	Percent   float64 `json:"percent"` // Percent off; negative if Grash was insulted
+	Until     string  `json:"until"`   // RFC3339 expiry
+}
+
+// Active reports whether the deal can still be used
+func (d HaggleDeal) Active(now time.Time) bool {
+	until, err := time.Parse(time.RFC3339, d.Until)
+	return err == nil && now.Before(until)
+}
+
+// priceModifiers is everything that can move a price away from the list price
+type priceModifiers struct {
+	Reputation map[string]int `json:"reputation"` // Character file name -> standing with Grash
+	Sales      []SaleEvent    `json:"sales"`
+	Deals      []HaggleDeal   `json:"deals"`
+}
+
+// modifiersMu serializes read-modify-write of the price modifiers file
+var modifiersMu sync.Mutex
+
+// loadPriceModifiers reads the price modifiers file. A missing file means no modifiers.
+func loadPriceModifiers() (*priceModifiers, error) {
+	mods := &priceModifiers{Reputation: make(map[string]int)}
+
+	data, err := os.ReadFile(config.DataPaths.PriceModifiers)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return mods, nil
+		}
+		return nil, fmt.Errorf("failed to read price modifiers: %w", err)
+	}
+
+	if err := json.Unmarshal(data, mods); err != nil {
+		return nil, fmt.Errorf("failed to parse price modifiers: %w", err)
+	}
+	if mods.Reputation == nil {
+		mods.Reputation = make(map[string]int)
+	}
+	return mods, nil
+}
+
+// savePriceModifiers writes the price modifiers file, dropping sales and deals that have ended
+func savePriceModifiers(mods *priceModifiers) error {
+	now := time.Now()
+	var sales []SaleEvent
+	for _, sale := range mods.Sales {
+		if sale.Active(now) {
+			sales = append(sales, sale)
+		}
+	}
+	var deals []HaggleDeal
+	for _, deal := range mods.Deals {
+		if deal.Active(now) {
+			deals = append(deals, deal)
+		}
+	}
+	mods.Sales, mods.Deals = sales, deals
+
+	data, err := json.MarshalIndent(mods, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal price modifiers: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.PriceModifiers, data, 0644); err != nil {
+		return fmt.Errorf("failed to write price modifiers: %w", err)
+	}
+	return nil
+}
+
+// updatePriceModifiers loads, changes and saves the price modifiers under the lock
+func updatePriceModifiers(change func(mods *priceModifiers) error) error {
+	modifiersMu.Lock()
+	defer modifiersMu.Unlock()
+
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return err
+	}
+	if err := change(mods); err != nil {
+		return err
+	}
+	return savePriceModifiers(mods)
+}
+
+// GetReputation returns a character's standing with Grash
+func GetReputation(characterFile string) (int, error) {
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return 0, err
+	}
+	return mods.Reputation[characterFile], nil
+}
+
+// SetReputation sets a character's standing with Grash, clamped to config.Pricing.MaxReputation.
+// Returns the value stored.
+func SetReputation(characterFile string, value int) (int, error) {
+	limit := config.Pricing.MaxReputation
+	value = max(-limit, min(limit, value))
+	err := updatePriceModifiers(func(mods *priceModifiers) error {
+		if value == 0 {
+			delete(mods.Reputation, characterFile)
+		} else {
+			mods.Reputation[characterFile] = value
+		}
+		return nil
+	})
+	return value, err
+}
+
+// ListReputation returns every character with a non-zero standing, keyed by file name
+func ListReputation() (map[string]int, error) {
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return nil, err
+	}
+	return mods.Reputation, nil
+}
+
+// StartSale starts a sale event, replacing any sale with the same name
+func StartSale(sale SaleEvent) error {
+	return updatePriceModifiers(func(mods *priceModifiers) error {
+		var kept []SaleEvent
+		for _, existing := range mods.Sales {
+			if !strings.EqualFold(existing.Name, sale.Name) {
+				kept = append(kept, existing)
+			}
+		}
+		mods.Sales = append(kept, sale)
+		return nil
+	})
+}
+
+// EndSale ends a sale event by name
+func EndSale(name string) error {
+	return updatePriceModifiers(func(mods *priceModifiers) error {
+		var kept []SaleEvent
+		found := false
+		for _, sale := range mods.Sales {
+			if strings.EqualFold(sale.Name, name) {
+				found = true
+				continue
+			}
+			kept = append(kept, sale)
+		}
+		if !found {
+			return fmt.Errorf("no sale named '%s'", name)
+		}
+		mods.Sales = kept
+		return nil
+	})
+}
+
+// ActiveSales returns the sales running now, biggest first
+func ActiveSales() ([]SaleEvent, error) {
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return nil, err
+	}
+	var active []SaleEvent
+	now := time.Now()
+	for _, sale := range mods.Sales {
+		if sale.Active(now) {
+			active = append(active, sale)
+		}
+	}
+	sort.SliceStable(active, func(a, b int) bool { return active[a].Percent > active[b].Percent })
+	return active, nil
+}
+
+// GrantHaggleDeal records a haggled price for one character and item, replacing any earlier
+// deal on the same item. It lasts config.Pricing.HaggleDuration or until it's used.
+func GrantHaggleDeal(characterFile, itemName string, percent float64) (HaggleDeal, error) {
+	deal := HaggleDeal{
+		Character: characterFile,
+		Item:      itemName,
+		Percent:   percent,
+		Until:     time.Now().Add(config.Pricing.HaggleDuration).Format(time.RFC3339),
+	}
+	err := updatePriceModifiers(func(mods *priceModifiers) error {
+		mods.Deals = append(withoutDeal(mods.Deals, characterFile, itemName), deal)
+		return nil
+	})
+	return deal, err
+}
+
+// GetHaggleDeal returns a character's active deal on an item, or nil if there isn't one
+func GetHaggleDeal(characterFile, itemName string) (*HaggleDeal, error) {
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return nil, err
+	}
+	return findDeal(mods.Deals, characterFile, itemName, time.Now()), nil
+}
+
+// findDeal returns the active deal for a character and item
+func findDeal(deals []HaggleDeal, characterFile, itemName string, now time.Time) *HaggleDeal {
+	for _, deal := range deals {
+		if deal.Character == characterFile && strings.EqualFold(deal.Item, itemName) && deal.Active(now) {
+			return &deal
+		}
+	}
+	return nil
+}
+
+// withoutDeal drops a character's deal on an item
+func withoutDeal(deals []HaggleDeal, characterFile, itemName string) []HaggleDeal {
+	var kept []HaggleDeal
+	for _, deal := range deals {
+		if deal.Character != characterFile || !strings.EqualFold(deal.Item, itemName) {
+			kept = append(kept, deal)
+		}
+	}
+	return kept
+}
+
+// useHaggleDeals removes the deals a purchase just used
+func useHaggleDeals(characterFile string, quotes []Quote) error {
+	used := false
+	for _, quote := range quotes {
+		used = used || quote.Deal
+	}
+	if !used {
+		return nil
+	}
+	return updatePriceModifiers(func(mods *priceModifiers) error {
+		for _, quote := range quotes {
+			if quote.Deal {
+				mods.Deals = withoutDeal(mods.Deals, characterFile, quote.Item)
+			}
+		}
+		return nil
+	})
+}
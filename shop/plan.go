@@ -0,0 +1,366 @@
+package shop
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// planTTL is how long a shopping plan can be accepted after it's made
+const planTTL = time.Hour
+
+// maxPlanWeight caps the carry weight a plan can be asked to fill, in lb
+const maxPlanWeight = 100
+
+// maxPlanCopies is how many of one consumable a plan will buy
+const maxPlanCopies = 3
+
+// maxPlanEntries caps the optimizer's item list (each copy counts) to bound its memory use
+const maxPlanEntries = 80
+
+// maxPlanCostSteps caps the optimizer's cost resolution; bigger budgets are counted in coarser steps
+const maxPlanCostSteps = 1000
+
+// ShoppingPlan is an optimized shopping list waiting for the player to accept it
+type ShoppingPlan struct {
+	ID            string
+	Created       time.Time
+	CharacterFile string
+	CharacterName string
+	Goal          string
+	Budget        float64
+	MaxWeight     float64
+	Lines         []CartLine
+	TotalCost     float64
+	TotalWeight   float64
+}
+
+// goalProfiles map a kind of adventure to the item keywords that help with it
+var goalProfiles = map[string][]string{
+	"dungeon": {"torch", "lantern", "oil", "rope", "crowbar", "spikes", "tinderbox", "chalk", "mirror",
+		"pole", "caltrops", "ball bearings", "healing", "grappling hook", "climb", "darkvision", "light"},
+	"wilderness": {"rations", "bedroll", "tent", "waterskin", "rope", "tinderbox", "blanket", "hunting trap",
+		"healing", "pot iron", "traveler", "climb", "shovel"},
+	"combat": {"healing", "shield", "javelin", "dagger", "acid", "alchemists fire", "holy water", "net",
+		"caltrops", "heroism", "giant strength", "armor"},
+	"stealth": {"caltrops", "ball bearings", "grappling hook", "rope", "hooded", "costume", "climb",
+		"invisibility", "elvenkind", "silence", "poison"},
+	"undead":  {"holy water", "torch", "healing", "silver", "mace", "light"},
+	"water":   {"water breathing", "swimming", "rope", "waterskin", "net"},
+	"social":  {"fine", "perfume", "diplomat", "costume", "ink", "paper", "signet"},
+	"healing": {"healing", "healer", "antitoxin", "restorative"},
+}
+
+// goalAliases map words players use to a goal profile
+var goalAliases = map[string]string{
+	"dungeon": "dungeon", "crawl": "dungeon", "delve": "dungeon", "cave": "dungeon", "caves": "dungeon",
+	"ruin": "dungeon", "ruins": "dungeon", "tomb": "dungeon", "underdark": "dungeon", "mine": "dungeon",
+	"wilderness": "wilderness", "travel": "wilderness", "journey": "wilderness", "overland": "wilderness",
+	"camping": "wilderness", "forest": "wilderness", "trek": "wilderness", "expedition": "wilderness", "hike": "wilderness",
+	"combat": "combat", "fight": "combat", "battle": "combat", "war": "combat", "monster": "combat", "boss": "combat",
+	"stealth": "stealth", "heist": "stealth", "infiltrate": "stealth", "infiltration": "stealth", "sneak": "stealth",
+	"burglary": "stealth", "thief": "stealth", "spy": "stealth",
+	"undead": "undead", "crypt": "undead", "vampire": "undead", "ghost": "undead", "zombie": "undead", "graveyard": "undead",
+	"sea": "water", "ocean": "water", "underwater": "water", "swim": "water", "river": "water", "ship": "water", "lake": "water",
+	"social": "social", "court": "social", "gala": "social", "ball": "social", "diplomacy": "social", "noble": "social",
+	"heal": "healing", "healing": "healing", "survive": "healing", "survival": "healing",
+}
+
+var (
+	plans   = make(map[string]*ShoppingPlan)
+	plansMu sync.Mutex
+)
+
+// BuildPlan picks the most useful set of catalog items for a goal within the budget and
+// carry weight. Consumables can be bought up to maxPlanCopies times, each extra copy worth
+// half the one before. The choice is deterministic for the same catalog and inputs.
+func BuildPlan(characterFile string, char *Character, budget, maxWeight float64, goal string) (*ShoppingPlan, error) {
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+	maxWeight = math.Min(maxWeight, maxPlanWeight)
+
+	type candidate struct {
+		item   Item
+		value  float64
+		copies int
+	}
+	level := ParseLevel(char.ClassLevel)
+	now := time.Now()
+	// A special held for someone else holds its catalog copy too
+	held := make(map[string]bool)
+	for _, special := range catalog.SessionSpecials.Items {
+		if special.HeldFromCharacter(char.Name, now) {
+			held[strings.ToLower(special.Name)] = true
+		}
+	}
+	var candidates []candidate
+	seen := make(map[string]int)
+	for _, item := range catalog.Items {
+		if item.Cost <= 0 || item.Cost > budget || parseWeight(item.Weight) > maxWeight {
+			continue
+		}
+		if !CanBuyAtLevel(item, level) || item.SoldOut() || held[strings.ToLower(item.Name)] {
+			continue
+		}
+		if len(ClassWarnings(char, item)) > 0 {
+			continue
+		}
+		consumable := item.IsConsumable()
+		if !consumable && ownsItem(char, item.Name) {
+			continue
+		}
+
+		value := goalValue(goal, item)
+		if value <= 0 {
+			continue
+		}
+		copies := 1
+		if consumable {
+			copies = maxPlanCopies
+		}
+		if item.Stock != nil {
+			copies = min(copies, *item.Stock)
+		}
+
+		// Some items are on more than one shelf; keep the cheaper listing
+		key := strings.ToLower(item.Name)
+		if idx, ok := seen[key]; ok {
+			if item.Cost < candidates[idx].item.Cost {
+				candidates[idx] = candidate{item, value, copies}
+			}
+			continue
+		}
+		seen[key] = len(candidates)
+		candidates = append(candidates, candidate{item, value, copies})
+	}
+
+	// Most useful first, so the entry cap drops the least useful items
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if candidates[a].value != candidates[b].value {
+			return candidates[a].value > candidates[b].value
+		}
+		return candidates[a].item.Name < candidates[b].item.Name
+	})
+
+	var entries []planEntry
+	for idx, c := range candidates {
+		for k := 0; k < c.copies && len(entries) < maxPlanEntries; k++ {
+			entries = append(entries, planEntry{
+				index:  idx,
+				cost:   c.item.Cost,
+				weight: parseWeight(c.item.Weight),
+				value:  c.value / math.Pow(2, float64(k)),
+			})
+		}
+	}
+
+	plan := &ShoppingPlan{
+		ID:            strconv.FormatInt(time.Now().UnixNano(), 36),
+		Created:       time.Now(),
+		CharacterFile: characterFile,
+		CharacterName: char.Name,
+		Goal:          goal,
+		Budget:        budget,
+		MaxWeight:     maxWeight,
+	}
+
+	counts := make(map[int]int)
+	var order []int
+	for _, e := range optimizePlan(entries, budget, maxWeight) {
+		if counts[e.index] == 0 {
+			order = append(order, e.index)
+		}
+		counts[e.index]++
+	}
+	for _, idx := range order {
+		item := candidates[idx].item
+		plan.Lines = append(plan.Lines, CartLine{Item: item, Quantity: counts[idx]})
+		plan.TotalCost += item.Cost * float64(counts[idx])
+		plan.TotalWeight += parseWeight(item.Weight) * float64(counts[idx])
+	}
+	return plan, nil
+}
+
+// planEntry is one copy of a candidate item for the optimizer
+type planEntry struct {
+	index  int // Index into the candidate list
+	cost   float64
+	weight float64
+	value  float64
+}
+
+// optimizePlan solves the 0/1 knapsack over cost and weight and returns the chosen entries.
+// Costs round up to the cost step and weights up to half a pound, so a plan never goes over.
+func optimizePlan(entries []planEntry, budget, maxWeight float64) []planEntry {
+	costStep := math.Max(1, math.Ceil(budget/maxPlanCostSteps))
+	capCost := int(budget / costStep)
+	capWeight := int(maxWeight * 2)
+	if capCost <= 0 || len(entries) == 0 {
+		return nil
+	}
+
+	width := capWeight + 1
+	best := make([]float64, (capCost+1)*width)
+	keep := make([]bool, len(entries)*(capCost+1)*width)
+
+	for n, e := range entries {
+		cost := int(math.Ceil(e.cost / costStep))
+		weight := int(math.Ceil(e.weight * 2))
+		if cost > capCost || weight > capWeight {
+			continue
+		}
+		base := n * (capCost + 1) * width
+		for c := capCost; c >= cost; c-- {
+			for w := capWeight; w >= weight; w-- {
+				if with := best[(c-cost)*width+w-weight] + e.value; with > best[c*width+w] {
+					best[c*width+w] = with
+					keep[base+c*width+w] = true
+				}
+			}
+		}
+	}
+
+	// Walk back through the choices from the full budget and weight
+	var chosen []planEntry
+	c, w := capCost, capWeight
+	for n := len(entries) - 1; n >= 0; n-- {
+		if keep[n*(capCost+1)*width+c*width+w] {
+			chosen = append(chosen, entries[n])
+			c -= int(math.Ceil(entries[n].cost / costStep))
+			w -= int(math.Ceil(entries[n].weight * 2))
+		}
+	}
+	sort.SliceStable(chosen, func(a, b int) bool { return chosen[a].index < chosen[b].index })
+	return chosen
+}
+
+// goalValue scores how much an item helps with a goal, from keyword matches on its name
+// (worth more) and description
+func goalValue(goal string, item Item) float64 {
+	name := normalizeSearchText(item.Name)
+	description := normalizeSearchText(item.Description + " " + item.Type)
+
+	keywords := make(map[string]bool)
+	direct := make(map[string]bool)
+	for _, token := range searchTokens(normalizeSearchText(goal)) {
+		if profile, ok := goalAliases[token]; ok {
+			for _, keyword := range goalProfiles[profile] {
+				keywords[keyword] = true
+			}
+		}
+		if len(token) >= 4 {
+			direct[token] = true
+		}
+	}
+
+	value := 0.0
+	for keyword := range keywords {
+		switch {
+		case strings.Contains(name, keyword):
+			value += 3
+		case strings.Contains(description, keyword):
+			value += 1
+		}
+	}
+	for token := range direct {
+		if strings.Contains(name, token) {
+			value += 2
+		}
+	}
+	return value
+}
+
+// parseWeight reads a weight string like "2 lb.", "1/2 lb." or "58½ lb." in pounds.
+// Blank or "—" weighs nothing.
+func parseWeight(weight string) float64 {
+	weight = strings.TrimSpace(weight)
+	if weight == "" || weight == "—" {
+		return 0
+	}
+	if idx := strings.Index(weight, "lb"); idx >= 0 {
+		weight = strings.TrimSpace(weight[:idx])
+	}
+
+	total := 0.0
+	if strings.HasSuffix(weight, "½") {
+		total += 0.5
+		weight = strings.TrimSuffix(weight, "½")
+	}
+	if num, den, ok := strings.Cut(weight, "/"); ok {
+		n, _ := strconv.ParseFloat(num, 64)
+		d, _ := strconv.ParseFloat(den, 64)
+		if d > 0 {
+			total += n / d
+		}
+		return total
+	}
+	if n, err := strconv.ParseFloat(strings.TrimSpace(weight), 64); err == nil {
+		total += n
+	}
+	return total
+}
+
+// StorePlan keeps a plan until it's accepted or expires
+func StorePlan(plan *ShoppingPlan) {
+	plansMu.Lock()
+	defer plansMu.Unlock()
+	for id, p := range plans {
+		if time.Since(p.Created) > planTTL {
+			delete(plans, id)
+		}
+	}
+	plans[plan.ID] = plan
+}
+
+// GetPlan returns a pending plan by ID
+func GetPlan(id string) (*ShoppingPlan, error) {
+	plansMu.Lock()
+	defer plansMu.Unlock()
+
+	plan, ok := plans[id]
+	if !ok || time.Since(plan.Created) > planTTL {
+		return nil, fmt.Errorf("this shopping list has expired - run /plan again")
+	}
+	return plan, nil
+}
+
+// DiscardPlan throws away a pending plan
+func DiscardPlan(id string) {
+	plansMu.Lock()
+	defer plansMu.Unlock()
+	delete(plans, id)
+}
+
+// AcceptPlan buys everything on a plan in one transaction and returns what each line cost
+func AcceptPlan(id string) ([]Quote, error) {
+	plan, err := GetPlan(id)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := PurchaseItems(plan.CharacterFile, plan.Lines, "Between sessions")
+	if err != nil {
+		return nil, err
+	}
+	DiscardPlan(id)
+	return quotes, nil
+}
+
+// FormatLines returns the plan as a bulleted shopping list
+func (p *ShoppingPlan) FormatLines() string {
+	var sb strings.Builder
+	for _, line := range p.Lines {
+		sb.WriteString(fmt.Sprintf("• %d× **%s** - %s gp", line.Quantity, line.Item.Name,
+			FormatCost(line.Item.Cost*float64(line.Quantity))))
+		if w := parseWeight(line.Item.Weight) * float64(line.Quantity); w > 0 {
+			sb.WriteString(fmt.Sprintf(" (%s lb)", strconv.FormatFloat(w, 'f', -1, 64)))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// PriceBook freezes the rolled price of each magic item for one pricing period,
+// so the shop quotes the same price until the period rolls over
+type PriceBook struct {
+	Period string         `json:"period"`
+	Prices map[string]int `json:"prices"` // Item name -> price in GP
+}
+
+var priceBook *PriceBook
+var priceBookDirty bool
+var priceBookMu sync.Mutex
+
+// CurrentPricePeriod returns the pricing period, which follows the rotation period
+func CurrentPricePeriod() string {
+	return CurrentRotationPeriod()
+}
+
+// loadPriceBook reads the price book from disk, starting a fresh one if the
+// file is missing or belongs to an older period
+func loadPriceBook(period string) *PriceBook {
+	book := &PriceBook{Period: period, Prices: make(map[string]int)}
+
+	data, err := os.ReadFile(config.DataPaths.PriceBook)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("could not read price book, starting fresh", "error", err)
+		}
+		return book
+	}
+
+	var stored PriceBook
+	if err := json.Unmarshal(data, &stored); err != nil {
+		slog.Warn("could not parse price book, starting fresh", "error", err)
+		return book
+	}
+	if stored.Period != period || stored.Prices == nil {
+		slog.Info("price book period rolled over", "old", stored.Period, "new", period)
+		return book
+	}
+
+	return &stored
+}
+
+// priceBookPrice returns the frozen price for an item, rolling and recording one if needed.
+// Rolls are seeded by period and item name, so even a lost price book reproduces the same prices.
+func priceBookPrice(item MagicItem) int {
+	priceBookMu.Lock()
+	defer priceBookMu.Unlock()
+
+	period := CurrentPricePeriod()
+	if priceBook == nil || priceBook.Period != period {
+		priceBook = loadPriceBook(period)
+	}
+
+	if price, ok := priceBook.Prices[item.Name]; ok {
+		return price
+	}
+	// Fall back to a case-insensitive match in case the GM edited the file by hand
+	key := strings.ToLower(item.Name)
+	for name, price := range priceBook.Prices {
+		if strings.ToLower(name) == key {
+			return price
+		}
+	}
+
+	price := RollPriceForItemSeeded(item, priceSeed(period, item.Name))
+	priceBook.Prices[item.Name] = price
+	priceBookDirty = true
+	return price
+}
+
+// priceSeed derives a stable seed from the period and item name
+func priceSeed(period, name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(period + "|" + strings.ToLower(name)))
+	return h.Sum64()
+}
+
+// SavePriceBook writes any newly rolled prices to disk
+func SavePriceBook() error {
+	priceBookMu.Lock()
+	defer priceBookMu.Unlock()
+
+	if priceBook == nil || !priceBookDirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(priceBook, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal price book: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.PriceBook, data, 0644); err != nil {
+		return fmt.Errorf("failed to write price book: %w", err)
+	}
+
+	priceBookDirty = false
+	return nil
+}
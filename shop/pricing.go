@@ -2,10 +2,14 @@ package shop
 
 import (
 	"fmt"
+	"math"
 	"math/rand/v2"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
 )
 
 // PriceTier defines the price range for a rarity level
@@ -47,6 +51,21 @@ func GetPriceTier(rarity string) *PriceTier {
 	return &PricingTable[1]
 }
 
+// RarityRank returns the position of a rarity in the pricing table (Common = 0),
+// or -1 for mundane items with no recognised rarity
+func RarityRank(rarity string) int {
+	if rarity == "" {
+		return -1
+	}
+	rarity = normalizeRarity(rarity)
+	for i, tier := range PricingTable {
+		if strings.EqualFold(tier.Rarity, rarity) {
+			return i
+		}
+	}
+	return -1
+}
+
 // GetConsumablePriceTier returns the consumable price tier for a given rarity
 func GetConsumablePriceTier(rarity string) *PriceTier {
 	rarity = normalizeRarity(rarity)
@@ -61,13 +80,23 @@ func GetConsumablePriceTier(rarity string) *PriceTier {
 // RollPrice generates a random price within the tier's range
 // Uses a weighted distribution favoring the middle of the range
 func (t *PriceTier) RollPrice() int {
+	return t.rollPrice(rand.IntN)
+}
+
+// RollPriceSeeded is RollPrice using the given random source, so the same seed gives the same price
+func (t *PriceTier) RollPriceSeeded(r *rand.Rand) int {
+	return t.rollPrice(r.IntN)
+}
+
+// rollPrice does the bell curve roll with the given IntN function
+func (t *PriceTier) rollPrice(intN func(int) int) int {
 	if t.Min == t.Max {
 		return t.Min
 	}
 
 	// Roll 2d100-style for bell curve distribution toward middle
-	roll1 := rand.IntN(t.Max-t.Min+1) + t.Min
-	roll2 := rand.IntN(t.Max-t.Min+1) + t.Min
+	roll1 := intN(t.Max-t.Min+1) + t.Min
+	roll2 := intN(t.Max-t.Min+1) + t.Min
 	avg := (roll1 + roll2) / 2
 
 	// Round to nice numbers
@@ -85,40 +114,114 @@ func (t *PriceTier) RollPriceLinear() int {
 
 // RollPriceForItem determines price based on item rarity, using consumable table for potions/scrolls/ammo
 func RollPriceForItem(item MagicItem) int {
-	name := strings.ToLower(item.Name)
-	isConsumable := strings.Contains(name, "potion") ||
-		strings.Contains(name, "scroll") ||
-		strings.Contains(name, "ammunition") ||
-		strings.Contains(name, "oil") ||
-		strings.Contains(name, "elixir") ||
-		strings.Contains(name, "philter")
+	return priceTierForItem(item).RollPrice()
+}
+
+// RollPriceForItemSeeded is RollPriceForItem with a deterministic seed
+func RollPriceForItemSeeded(item MagicItem, seed uint64) int {
+	r := rand.New(rand.NewPCG(seed, seed>>1|1))
+	return priceTierForItem(item).RollPriceSeeded(r)
+}
+
+// consumableKeywords mark one-use magic items by name (potions, scrolls, ammo)
+var consumableKeywords = []string{"potion", "scroll", "ammunition", "oil", "elixir", "philter"}
+
+// mundaneConsumables are one-use adventuring gear that doesn't match consumableKeywords
+var mundaneConsumables = map[string]bool{
+	"acid": true, "alchemist's fire": true, "antitoxin": true, "holy water": true,
+	"rations": true, "torch": true, "candle": true, "poison, basic": true,
+}
 
-	var tier *PriceTier
-	if isConsumable {
-		tier = GetConsumablePriceTier(item.Rarity)
-	} else {
-		tier = GetPriceTier(item.Rarity)
+// isConsumableName reports whether an item name marks a one-use item. Keywords match whole
+// words (or their plurals), so "Boiling" isn't an oil.
+func isConsumableName(name string) bool {
+	for _, word := range strings.Fields(normalizeSearchText(name)) {
+		for _, keyword := range consumableKeywords {
+			if word == keyword || word == keyword+"s" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsConsumable reports whether an item is used up when used. A "consumable" flag in the data
+// files decides; otherwise potions, scrolls, ammunition, torches, rations and the like are.
+func (i Item) IsConsumable() bool {
+	if i.Consumable != nil {
+		return *i.Consumable
+	}
+	if len(i.Contents) > 0 {
+		return false
+	}
+	return i.Category == "potions" || i.Category == "magic_potions" ||
+		isConsumableName(i.Name) || mundaneConsumables[strings.ToLower(i.Name)]
+}
+
+// IsConsumable reports whether a magic item is used up when used, either flagged in its
+// reference file or by name
+func (m *MagicItem) IsConsumable() bool {
+	if m.Consumable != nil {
+		return *m.Consumable
 	}
+	return isConsumableName(m.Name)
+}
 
-	return tier.RollPrice()
+// priceTierForItem picks the consumable or standard price tier for an item
+func priceTierForItem(item MagicItem) *PriceTier {
+	if item.IsConsumable() {
+		return GetConsumablePriceTier(item.Rarity)
+	}
+	return GetPriceTier(item.Rarity)
 }
 
 // MagicItem represents an item from the magic item reference files
 type MagicItem struct {
-	Name        string `json:"name"`
-	Type        string `json:"type,omitempty"`
-	Rarity      string `json:"rarity"`
-	Attunement  string `json:"attunement,omitempty"`
-	Description string `json:"description"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type,omitempty"`
+	Rarity      string  `json:"rarity"`
+	Attunement  string  `json:"attunement,omitempty"`
+	Description string  `json:"description"`
+	Cost        float64 `json:"cost,omitempty"`       // Optional fixed price in GP, rolled if unset
+	Stock       *int    `json:"stock,omitempty"`      // Optional limited stock, unlimited if unset
+	Consumable  *bool   `json:"consumable,omitempty"` // Optional; potions, scrolls and ammo are consumable by name
 }
 
-// ToShopItem converts a MagicItem to a shop Item with a rolled price
+// PriceForMagicItem returns the item's fixed price if it has one,
+// otherwise the price frozen in this period's price book
+func PriceForMagicItem(item MagicItem) int {
+	return PriceForMagicItemInPeriod(item, CurrentPricePeriod())
+}
+
+// PriceForMagicItemInPeriod returns the item's fixed price if it has one, otherwise its
+// price in the given pricing period: the price book's for the current period, or the same
+// seeded roll the book would have made for any other
+func PriceForMagicItemInPeriod(item MagicItem, period string) int {
+	if item.Cost > 0 {
+		return int(item.Cost)
+	}
+	if period == CurrentPricePeriod() {
+		return priceBookPrice(item)
+	}
+	return RollPriceForItemSeeded(item, priceSeed(period, item.Name))
+}
+
+// ToShopItem converts a MagicItem to a shop Item priced via PriceForMagicItem
 func (m *MagicItem) ToShopItem() Item {
+	return m.ToShopItemInPeriod(CurrentPricePeriod())
+}
+
+// ToShopItemInPeriod converts a MagicItem to a shop Item priced for the given pricing period
+func (m *MagicItem) ToShopItemInPeriod(period string) Item {
 	return Item{
 		Name:        m.Name,
 		Description: m.Description,
 		Rarity:      m.Rarity,
-		Cost:        float64(RollPriceForItem(*m)),
+		Type:        m.Type,
+		Attunement:  m.Attunement,
+		Cost:        float64(PriceForMagicItemInPeriod(*m, period)),
+		Stock:       m.Stock,
+		Consumable:  m.Consumable,
 	}
 }
 
@@ -225,11 +328,29 @@ func IsRarityAllowed(rarity string, level int) bool {
 	return false
 }
 
+// CanBuyAtLevel checks if a shop item is available to a character of the given level.
+// Mundane items have no rarity and are always available.
+func CanBuyAtLevel(item Item, level int) bool {
+	if item.Rarity == "" {
+		return true
+	}
+	return IsRarityAllowed(item.Rarity, level)
+}
+
 // levelRegex matches a number in a class_level string like "Paladin 5"
 var levelRegex = regexp.MustCompile(`\d+`)
 
-// ParseLevel extracts the numeric level from a class_level string (e.g. "Paladin 5" -> 5)
+// ParseLevel extracts the total character level from a class_level string
+// (e.g. "Paladin 5" -> 5, "Fighter 3 / Wizard 2" -> 5)
 func ParseLevel(classLevel string) int {
+	if entries := ParseClasses(classLevel); len(entries) > 0 {
+		total := 0
+		for _, entry := range entries {
+			total += entry.Level
+		}
+		return total
+	}
+
 	match := levelRegex.FindString(classLevel)
 	if match == "" {
 		return 1
@@ -240,3 +361,92 @@ func ParseLevel(classLevel string) int {
 	}
 	return level
 }
+
+// PriceModifier is one adjustment applied to an item's list price
+type PriceModifier struct {
+	Name    string  `json:"name"`    // e.g. "Reputation +2", "Midsummer Sale"
+	Percent float64 `json:"percent"` // Change in price; negative is a discount
+}
+
+// Quote is what a character pays for an item after the pricing pipeline
+type Quote struct {
+	Item      string
+	Quantity  int
+	Base      float64 // List price per item
+	Unit      float64 // Price per item after modifiers
+	Total     float64
+	Modifiers []PriceModifier
+	Deal      bool // A haggled deal was used
+}
+
+// QuotePrice runs the pricing pipeline for a character buying quantity of an item
+func QuotePrice(characterFile string, item Item, quantity int) (Quote, error) {
+	mods, err := loadPriceModifiers()
+	if err != nil {
+		return Quote{}, err
+	}
+	return quoteWith(mods, characterFile, item, quantity, time.Now()), nil
+}
+
+// quoteWith applies the modifiers in order: reputation with Grash, the best running sale,
+// bulk discount, then any haggled deal. Percentages add up, and the total discount is
+// capped at config.Pricing.MaxDiscount.
+func quoteWith(mods *priceModifiers, characterFile string, item Item, quantity int, now time.Time) Quote {
+	quote := Quote{Item: item.Name, Quantity: quantity, Base: item.Cost}
+
+	if rep := mods.Reputation[characterFile]; rep != 0 {
+		quote.Modifiers = append(quote.Modifiers, PriceModifier{
+			Name:    fmt.Sprintf("Reputation %+d", rep),
+			Percent: -float64(rep) * config.Pricing.ReputationStep,
+		})
+	}
+
+	var best *SaleEvent
+	for idx, sale := range mods.Sales {
+		if sale.Active(now) && sale.Covers(item) && (best == nil || sale.Percent > best.Percent) {
+			best = &mods.Sales[idx]
+		}
+	}
+	if best != nil {
+		quote.Modifiers = append(quote.Modifiers, PriceModifier{Name: best.Name, Percent: -best.Percent})
+	}
+
+	bulk, bulkQuantity := 0.0, 0
+	for minQuantity, percent := range config.Pricing.BulkDiscounts {
+		if quantity >= minQuantity && minQuantity > bulkQuantity {
+			bulk, bulkQuantity = percent, minQuantity
+		}
+	}
+	if bulk > 0 {
+		quote.Modifiers = append(quote.Modifiers, PriceModifier{
+			Name:    fmt.Sprintf("Bulk (%d+)", bulkQuantity),
+			Percent: -bulk,
+		})
+	}
+
+	// A failed haggle leaves a 0% deal that only stops the character trying again
+	if deal := findDeal(mods.Deals, characterFile, item.Name, now); deal != nil && deal.Percent != 0 {
+		quote.Modifiers = append(quote.Modifiers, PriceModifier{Name: "Haggled", Percent: -deal.Percent})
+		quote.Deal = true
+	}
+
+	change := 0.0
+	for _, mod := range quote.Modifiers {
+		change += mod.Percent
+	}
+	change = math.Max(change, -config.Pricing.MaxDiscount)
+
+	// Prices are kept to the silver piece
+	quote.Unit = math.Round(item.Cost*(100+change)/10) / 10
+	quote.Total = quote.Unit * float64(quantity)
+	return quote
+}
+
+// FormatModifiers describes price modifiers, e.g. "Reputation +2 -4%, Bulk (5+) -5%"
+func FormatModifiers(modifiers []PriceModifier) string {
+	var parts []string
+	for _, mod := range modifiers {
+		parts = append(parts, fmt.Sprintf("%s %+g%%", mod.Name, mod.Percent))
+	}
+	return strings.Join(parts, ", ")
+}
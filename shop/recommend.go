@@ -0,0 +1,230 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/ai"
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// recommendPicks is how many items /recommend suggests
+const recommendPicks = 3
+
+// Recommendation is one personal pick with the curator's reason
+type Recommendation struct {
+	Item   Item
+	Reason string
+}
+
+// recommendSystemPrompt is the system prompt for personal /recommend picks
+const recommendSystemPrompt = `You are a D&D 5e shop assistant recommending items to a single character.
+
+RULES:
+- Select exactly 3 items from the provided list
+- Pick items that match the character's class, backstory, and playstyle, and fill gaps in what they already own
+- Return ONLY valid JSON, no other text
+- The "name" field must be copied EXACTLY from the list. Do not invent items.
+
+RESPONSE FORMAT (JSON only):
+{
+  "picks": [
+    {"name": "Exact Item Name From List", "reason": "Brief reason why this suits them"}
+  ]
+}`
+
+// RecommendForCharacter asks the curator for personal picks the character can afford and use.
+// A budget of 0 means no limit; an empty category (or "all") means the whole catalog.
+func RecommendForCharacter(char *Character, budget float64, category string) ([]Recommendation, error) {
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	items := catalog.GetItemsByCategory(category)
+	if category == "specials" {
+		items = catalog.SessionSpecials.Items
+	}
+
+	candidates := recommendCandidates(char, items, budget)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	slog.Info("recommend candidates", "character", char.Name, "count", len(candidates))
+
+	// Best they can afford first, in case the curator and embeddings are unavailable
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Cost > candidates[b].Cost
+	})
+
+	// Narrow big lists to the items closest to the character
+	if len(candidates) > config.Curator.ShortlistSize {
+		shortlist, err := shortlistCatalogItems(char, candidates, config.Curator.ShortlistSize)
+		if err != nil {
+			slog.Warn("recommend shortlist unavailable, using most expensive items", "error", err)
+			candidates = candidates[:config.Curator.ShortlistSize]
+		} else {
+			candidates = shortlist
+		}
+	}
+
+	picks, err := askRecommendations(char, candidates)
+	if err != nil {
+		slog.Warn("recommend curator call failed, using shortlist order", "error", err)
+	}
+
+	// Top up from the shortlist if the curator came back short
+	for _, item := range candidates {
+		if len(picks) >= recommendPicks {
+			break
+		}
+		if !recommendationHas(picks, item.Name) {
+			picks = append(picks, Recommendation{Item: item, Reason: "Grash's pick from the shelves"})
+		}
+	}
+	return picks, nil
+}
+
+// recommendCandidates keeps items the character can afford, use and buy at their level,
+// that aren't sold out, held for someone else, or already in their inventory
+func recommendCandidates(char *Character, items []Item, budget float64) []Item {
+	level := ParseLevel(char.ClassLevel)
+	now := time.Now()
+
+	var candidates []Item
+	for _, item := range items {
+		if budget > 0 && item.Cost > budget {
+			continue
+		}
+		if !CanBuyAtLevel(item, level) || item.SoldOut() || item.HeldFromCharacter(char.Name, now) {
+			continue
+		}
+		if len(ClassWarnings(char, item)) > 0 || ownsItem(char, item.Name) {
+			continue
+		}
+		candidates = append(candidates, item)
+	}
+	return candidates
+}
+
+// ownsItem reports whether an inventory entry already matches the item
+func ownsItem(char *Character, name string) bool {
+	for _, entry := range char.CurrentInventory {
+		if scoreItemName(normalizeSearchText(entry), name) >= carriedMatchScore {
+			return true
+		}
+	}
+	return false
+}
+
+// shortlistCatalogItems ranks catalog items against the character by embedding similarity
+func shortlistCatalogItems(char *Character, items []Item, size int) ([]Item, error) {
+	byName := make(map[string]Item, len(items))
+	pool := make([]MagicItem, len(items))
+	for i, item := range items {
+		byName[item.Name] = item
+		description := item.Description
+		if description == "" {
+			description = ItemDetails(item)
+		}
+		pool[i] = MagicItem{Name: item.Name, Rarity: item.Rarity, Type: item.Type, Attunement: item.Attunement, Description: description}
+	}
+
+	shortlists, err := shortlistItems([]*Character{char}, pool, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var shortlist []Item
+	for _, item := range shortlists[char.Name] {
+		shortlist = append(shortlist, byName[item.Name])
+	}
+	return shortlist, nil
+}
+
+// askRecommendations asks the curator to choose from the candidates for one character
+func askRecommendations(char *Character, candidates []Item) ([]Recommendation, error) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## Character: %s (%s)\n", char.Name, char.ClassLevel))
+	sb.WriteString(fmt.Sprintf("- Backstory: %s\n", char.BackstorySummary))
+	if char.Playstyle != "" {
+		sb.WriteString(fmt.Sprintf("- Playstyle: %s\n", char.Playstyle))
+	}
+	if len(char.CurrentInventory) > 0 {
+		sb.WriteString(fmt.Sprintf("- Already owns: %s\n", strings.Join(char.CurrentInventory, ", ")))
+	}
+	sb.WriteString("\n## Items\n\n")
+	for _, item := range candidates {
+		sb.WriteString(fmt.Sprintf("- %s (%s gp", item.Name, FormatCost(item.Cost)))
+		if item.Rarity != "" {
+			sb.WriteString(", " + normalizeRarity(item.Rarity))
+		}
+		sb.WriteString(")\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nSelect exactly %d items. Return ONLY JSON.\n", recommendPicks))
+
+	conv := ai.NewConversation(curatorModel, recommendSystemPrompt)
+	conv.AddMessage("user", sb.String())
+
+	start := time.Now()
+	raw, err := conv.SendToOllamaWithTimeout(2*time.Minute, map[string]any{
+		"num_ctx": 4096,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ollama recommend call failed: %w", err)
+	}
+	slog.Info("ollama recommend response received", "duration", time.Since(start))
+
+	jsonBytes, err := extractJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recommend response: %w", err)
+	}
+	var resp struct {
+		Picks []CuratorItem `json:"picks"`
+	}
+	if err := json.Unmarshal(jsonBytes, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recommend response: %w", err)
+	}
+
+	// Keep only real, distinct candidates
+	poolNames := make(map[string]bool, len(candidates))
+	poolList := make([]string, 0, len(candidates))
+	byName := make(map[string]Item, len(candidates))
+	for _, item := range candidates {
+		lower := strings.ToLower(item.Name)
+		poolNames[lower] = true
+		poolList = append(poolList, lower)
+		byName[lower] = item
+	}
+
+	var picks []Recommendation
+	for _, pick := range resp.Picks {
+		name := strings.ToLower(pick.Name)
+		if !poolNames[name] {
+			name = fuzzyMatchPool(name, poolNames, poolList)
+		}
+		if name == "" || recommendationHas(picks, name) {
+			slog.Warn("recommend pick dropped", "item", pick.Name, "character", char.Name)
+			continue
+		}
+		picks = append(picks, Recommendation{Item: byName[name], Reason: pick.Reason})
+		if len(picks) == recommendPicks {
+			break
+		}
+	}
+	return picks, nil
+}
+
+// recommendationHas reports whether an item is already among the picks
+func recommendationHas(picks []Recommendation, name string) bool {
+	for _, pick := range picks {
+		if strings.EqualFold(pick.Item.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,9 @@ package shop
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
-	"os"
+	"math/rand/v2"
 	"strings"
 	"time"
 
@@ -27,6 +28,87 @@ func FormatSessionSpecials() string {
 	return FormatItemList(items)
 }
 
+// RotationPeriod returns the period key for a time and period length,
+// e.g. "2026-01" (month), "2026-W03" (week) or "2026-01-15" (day)
+func RotationPeriod(t time.Time, length string) string {
+	switch strings.ToLower(length) {
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "day":
+		return t.Format("2006-01-02")
+	default:
+		return t.Format("2006-01")
+	}
+}
+
+// CurrentRotationPeriod returns the period key for now using the configured period length
+func CurrentRotationPeriod() string {
+	return RotationPeriod(time.Now(), config.Rotation.Period)
+}
+
+// CurrentRotationLabel names the current rotation for players, e.g. "this week's rotation"
+func CurrentRotationLabel() string {
+	switch strings.ToLower(config.Rotation.Period) {
+	case "week":
+		return "this week's rotation"
+	case "day":
+		return "today's rotation"
+	default:
+		return "this month's rotation"
+	}
+}
+
+// GetRotation returns the rotation items for a period. The period string seeds the
+// shuffle, so the same period always produces the same items - no LLM required.
+func GetRotation(period string) ([]Item, error) {
+	allItems, err := LoadAllMagicItems()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load magic items: %w", err)
+	}
+
+	byName := make(map[string]MagicItem, len(allItems))
+	for _, item := range allItems {
+		byName[strings.ToLower(item.Name)] = item
+	}
+
+	// Shuffle a copy of the pool with a generator seeded by the period
+	pool := make([]string, len(config.Rotation.Items))
+	copy(pool, config.Rotation.Items)
+	h := fnv.New64a()
+	h.Write([]byte(period))
+	seed := h.Sum64()
+	r := rand.New(rand.NewPCG(seed, seed>>1|1))
+	r.Shuffle(len(pool), func(a, b int) {
+		pool[a], pool[b] = pool[b], pool[a]
+	})
+
+	var items []Item
+	for _, name := range pool {
+		if len(items) == config.Rotation.Size {
+			break
+		}
+		magicItem, ok := byName[strings.ToLower(name)]
+		if !ok {
+			slog.Warn("rotation pool item not found in magic item files", "item", name)
+			continue
+		}
+		item := magicItem.ToShopItemInPeriod(period)
+		item.Category = "monthly"
+		items = append(items, item)
+	}
+
+	if err := SavePriceBook(); err != nil {
+		slog.Warn("failed to save price book", "error", err)
+	}
+	return items, nil
+}
+
+// GetCurrentRotation returns the rotation items for the current period
+func GetCurrentRotation() ([]Item, error) {
+	return GetRotation(CurrentRotationPeriod())
+}
+
 // CuratorItem represents a single item recommendation from the curator LLM
 type CuratorItem struct {
 	Name   string `json:"name"`
@@ -55,19 +137,11 @@ func LoadAllMagicItems() ([]MagicItem, error) {
 
 	var allItems []MagicItem
 	for _, path := range paths {
-		data, err := os.ReadFile(path)
+		items, err := loadMagicItemsFromFile(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
 		}
-
-		var file struct {
-			Items []MagicItem `json:"items"`
-		}
-		if err := json.Unmarshal(data, &file); err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
-		}
-
-		allItems = append(allItems, file.Items...)
+		allItems = append(allItems, items...)
 	}
 
 	return allItems, nil
@@ -84,6 +158,9 @@ func FilterItemsByLevel(items []MagicItem, level int) []MagicItem {
 	return filtered
 }
 
+// curatorModel is the Ollama model used for specials curation
+const curatorModel = "llama3.1:8b"
+
 // curatorSystemPrompt is the system prompt for the item curator (separate from Grash personality)
 const curatorSystemPrompt = `You are a D&D 5e magic item curator. Your job is to select personalized magic items for each character in a party.
 
@@ -111,13 +188,21 @@ RESPONSE FORMAT (JSON only):
   ]
 }`
 
-// buildCuratorMessage constructs the user message with character profiles and item pool
-func buildCuratorMessage(characters []*Character, items []MagicItem) string {
+// buildCuratorMessage constructs the user message with character profiles and item pools.
+// Each character gets their own pool: their embedding shortlist, or everything their level
+// and class allow when shortlists aren't available.
+func buildCuratorMessage(characters []*Character, pools map[string][]MagicItem) string {
 	var sb strings.Builder
 
 	sb.WriteString("## Characters\n\n")
 	for _, char := range characters {
+		level := ParseLevel(char.ClassLevel)
 		sb.WriteString(fmt.Sprintf("### %s (%s)\n", char.Name, char.ClassLevel))
+		sb.WriteString(fmt.Sprintf("- Eligible rarities (level %d): ONLY pick from %s\n",
+			level, strings.Join(GetAllowedRarities(level), ", ")))
+		if prof := ProficiencySummary(char); prof != "" {
+			sb.WriteString(fmt.Sprintf("- Proficient with: %s (skip armor/weapons outside this, and class-restricted attunement for other classes)\n", prof))
+		}
 		sb.WriteString(fmt.Sprintf("- Backstory: %s\n", char.BackstorySummary))
 		if char.Playstyle != "" {
 			sb.WriteString(fmt.Sprintf("- Playstyle: %s\n", char.Playstyle))
@@ -125,16 +210,14 @@ func buildCuratorMessage(characters []*Character, items []MagicItem) string {
 		if len(char.CurrentInventory) > 0 {
 			sb.WriteString(fmt.Sprintf("- Current inventory: %s\n", strings.Join(char.CurrentInventory, ", ")))
 		}
+		sb.WriteString("- Item pool for this character (pick ONLY from these):\n")
+		for _, item := range pools[char.Name] {
+			sb.WriteString(fmt.Sprintf("  - %s (%s)\n", item.Name, normalizeRarity(item.Rarity)))
+		}
 		sb.WriteString("\n")
 	}
 
-	sb.WriteString("## Available Item Pool\n\n")
-	for _, item := range items {
-		sb.WriteString(fmt.Sprintf("- %s (%s)\n", item.Name, normalizeRarity(item.Rarity)))
-	}
-
-	sb.WriteString(fmt.Sprintf("\nSelect exactly 4 items for each of the %d characters. Return ONLY JSON.\n", len(characters)))
-
+	sb.WriteString(fmt.Sprintf("Select exactly %d items for each of the %d characters, each from their own item pool. Return ONLY JSON.\n", picksPerCharacter, len(characters)))
 	return sb.String()
 }
 
@@ -180,8 +263,9 @@ func extractJSON(raw string) ([]byte, error) {
 }
 
 // validateSelections checks curator selections against the actual item pool,
-// dropping any hallucinated items and logging warnings
-func validateSelections(response *CuratorResponse, pool []MagicItem) {
+// dropping any hallucinated items and logging warnings. Items already in taken count as
+// duplicates; accepted items are added to it. Returns the names that were dropped.
+func validateSelections(response *CuratorResponse, pool []MagicItem, taken map[string]bool) []string {
 	// Build lookup structures
 	poolNames := make(map[string]bool, len(pool))
 	poolList := make([]string, 0, len(pool))
@@ -191,7 +275,7 @@ func validateSelections(response *CuratorResponse, pool []MagicItem) {
 		poolList = append(poolList, lower)
 	}
 
-	seen := make(map[string]bool)
+	var rejected []string
 	for i := range response.Selections {
 		var valid []CuratorItem
 		for _, ci := range response.Selections[i].Items {
@@ -208,21 +292,279 @@ func validateSelections(response *CuratorResponse, pool []MagicItem) {
 				slog.Warn("curator hallucinated item, dropping",
 					"item", ci.Name,
 					"character", response.Selections[i].Character)
+				rejected = append(rejected, ci.Name)
 				continue
 			}
-			if seen[matched] {
+			if taken[matched] {
 				slog.Warn("curator duplicated item across characters, dropping",
 					"item", ci.Name,
 					"character", response.Selections[i].Character)
+				rejected = append(rejected, ci.Name)
 				continue
 			}
-			seen[matched] = true
+			taken[matched] = true
 			// Fix the name to the canonical pool name
 			ci.Name = canonicalName(matched, pool)
 			valid = append(valid, ci)
 		}
 		response.Selections[i].Items = valid
 	}
+	return rejected
+}
+
+// picksPerCharacter is how many specials the curator picks for each character
+const picksPerCharacter = 4
+
+// curatorRetries is how many times the curator is re-prompted for picks that were dropped
+const curatorRetries = 2
+
+// Curator context budget. num_ctx is sized from the prompt plus room for the reply, in
+// steps of curatorContextStep, and never goes past curatorMaxContext; pools are trimmed
+// until the prompt fits. The timeout grows with the context so a long prompt isn't cut off.
+const (
+	curatorMinContext     = 4096
+	curatorMaxContext     = 32768
+	curatorContextStep    = 1024
+	curatorReplyTokens    = 512 // Per character: picks plus their reasons, as JSON
+	curatorBaseTimeout    = time.Minute
+	curatorTimeoutPerStep = 30 * time.Second
+)
+
+// estimateTokens roughly counts the tokens in some text, erring high (about 3 characters
+// per token, where English usually runs closer to 4)
+func estimateTokens(text string) int {
+	return (len(text) + 2) / 3
+}
+
+// curatorBudget returns the num_ctx and timeout for sending a conversation whose reply
+// covers the given number of characters
+func curatorBudget(conv *ai.Conversation, characters int) (int, time.Duration) {
+	tokens := characters * curatorReplyTokens
+	for _, msg := range conv.Messages {
+		tokens += estimateTokens(msg.Content)
+	}
+	numCtx := (tokens + curatorContextStep - 1) / curatorContextStep * curatorContextStep
+	numCtx = max(curatorMinContext, min(numCtx, curatorMaxContext))
+	timeout := curatorBaseTimeout + time.Duration(numCtx/curatorContextStep)*curatorTimeoutPerStep
+	return numCtx, timeout
+}
+
+// fitCuratorPools trims every character's pool until the curator prompt fits the context
+// budget, leaving headroom for the replies and re-prompts. Shortlists are ranked, so
+// trimming keeps each character's closest items. Returns the prompt for the trimmed pools.
+func fitCuratorPools(characters []*Character, pools map[string][]MagicItem) string {
+	// Replies and up to curatorRetries refill rounds share the budget with the prompt
+	budget := curatorMaxContext - (curatorRetries+1)*len(characters)*curatorReplyTokens*2
+	userMsg := buildCuratorMessage(characters, pools)
+	for estimateTokens(curatorSystemPrompt+userMsg) > budget {
+		largest := 0
+		for _, items := range pools {
+			largest = max(largest, len(items))
+		}
+		if largest <= picksPerCharacter {
+			slog.Warn("curator prompt is over budget even at the minimum pool size", "estimated_tokens", estimateTokens(curatorSystemPrompt+userMsg))
+			break
+		}
+		size := max(picksPerCharacter, largest*3/4)
+		for name, items := range pools {
+			if len(items) > size {
+				pools[name] = items[:size]
+			}
+		}
+		userMsg = buildCuratorMessage(characters, pools)
+	}
+	return userMsg
+}
+
+// mergeSelections validates a round of curator picks and adds them to the lineup,
+// topping each known character up to picksPerCharacter. Picks above a character's own
+// level are dropped too. Returns the names that were dropped.
+func mergeSelections(lineup, round *CuratorResponse, characters []*Character, pool []MagicItem) []string {
+	rejected := validateSelections(round, pool, takenNames(lineup))
+
+	for _, sel := range round.Selections {
+		var char *Character
+		for _, c := range characters {
+			if strings.EqualFold(c.Name, sel.Character) {
+				char = c
+				break
+			}
+		}
+		if char == nil {
+			slog.Warn("curator picked for an unknown character, dropping", "character", sel.Character)
+			continue
+		}
+
+		target := lineupSelection(lineup, char.Name)
+		for _, ci := range sel.Items {
+			if len(target.Items) == picksPerCharacter {
+				break
+			}
+			if item, ok := poolItem(pool, ci.Name); ok && !canPick(char, item) {
+				slog.Warn("curator picked an item the character can't use, dropping",
+					"item", ci.Name, "character", char.Name, "rarity", item.Rarity)
+				rejected = append(rejected, ci.Name)
+				continue
+			}
+			target.Items = append(target.Items, ci)
+		}
+	}
+	return rejected
+}
+
+// canPick reports whether a magic item's rarity is allowed at the character's own level
+// and their class can use it (proficiency and attunement restrictions)
+func canPick(char *Character, item MagicItem) bool {
+	return IsRarityAllowed(item.Rarity, ParseLevel(char.ClassLevel)) && ClassCanUse(char, item)
+}
+
+// eligiblePools splits the pool into the items each character can pick, keyed by name.
+// Each character gets at most size items, sampled with a seed of their name and today's
+// date, so the prompt stays within the curator's context however large the pool grows.
+func eligiblePools(characters []*Character, pool []MagicItem, size int) map[string][]MagicItem {
+	today := time.Now().Format("2006-01-02")
+	pools := make(map[string][]MagicItem, len(characters))
+	for _, char := range characters {
+		var eligible []MagicItem
+		for _, item := range pool {
+			if canPick(char, item) {
+				eligible = append(eligible, item)
+			}
+		}
+		if size > 0 && len(eligible) > size {
+			h := fnv.New64a()
+			h.Write([]byte(today + ":" + char.Name))
+			seed := h.Sum64()
+			r := rand.New(rand.NewPCG(seed, seed>>1|1))
+			r.Shuffle(len(eligible), func(a, b int) {
+				eligible[a], eligible[b] = eligible[b], eligible[a]
+			})
+			eligible = eligible[:size]
+		}
+		pools[char.Name] = eligible
+	}
+	return pools
+}
+
+// poolItem looks up an item in the pool by name
+func poolItem(pool []MagicItem, name string) (MagicItem, bool) {
+	for _, item := range pool {
+		if strings.EqualFold(item.Name, name) {
+			return item, true
+		}
+	}
+	return MagicItem{}, false
+}
+
+// lineupSelection returns the lineup's selection for a character, adding an empty one if needed
+func lineupSelection(lineup *CuratorResponse, character string) *CuratorSelection {
+	for i := range lineup.Selections {
+		if strings.EqualFold(lineup.Selections[i].Character, character) {
+			return &lineup.Selections[i]
+		}
+	}
+	lineup.Selections = append(lineup.Selections, CuratorSelection{Character: character})
+	return &lineup.Selections[len(lineup.Selections)-1]
+}
+
+// takenNames returns the lowercase names of every item already in the lineup
+func takenNames(lineup *CuratorResponse) map[string]bool {
+	taken := make(map[string]bool)
+	for _, sel := range lineup.Selections {
+		for _, ci := range sel.Items {
+			taken[strings.ToLower(ci.Name)] = true
+		}
+	}
+	return taken
+}
+
+// missingPicks returns how many picks each character is short of picksPerCharacter
+func missingPicks(lineup *CuratorResponse, characters []*Character) map[string]int {
+	missing := make(map[string]int)
+	for _, char := range characters {
+		have := 0
+		for _, sel := range lineup.Selections {
+			if strings.EqualFold(sel.Character, char.Name) {
+				have += len(sel.Items)
+			}
+		}
+		if have < picksPerCharacter {
+			missing[char.Name] = picksPerCharacter - have
+		}
+	}
+	return missing
+}
+
+// buildRefillMessage asks the curator to fill only the slots that validation left empty
+func buildRefillMessage(characters []*Character, missing map[string]int, rejected []string, lineup *CuratorResponse) string {
+	var sb strings.Builder
+
+	sb.WriteString("Some of your picks could not be used.\n\n")
+	if len(rejected) > 0 {
+		sb.WriteString(fmt.Sprintf("Rejected (not in the item pool, or already picked): %s\n", strings.Join(rejected, ", ")))
+	}
+
+	var taken []string
+	for _, sel := range lineup.Selections {
+		for _, ci := range sel.Items {
+			taken = append(taken, ci.Name)
+		}
+	}
+	if len(taken) > 0 {
+		sb.WriteString(fmt.Sprintf("Already taken, do not pick again: %s\n", strings.Join(taken, ", ")))
+	}
+
+	sb.WriteString("\nPick more items from the same item pools, ONLY for these characters:\n")
+	for _, char := range characters {
+		if n := missing[char.Name]; n > 0 {
+			sb.WriteString(fmt.Sprintf("- %s: exactly %d more item(s)\n", char.Name, n))
+		}
+	}
+	sb.WriteString("\nUse the same JSON format and copy names EXACTLY from the pool. Return ONLY JSON.\n")
+
+	return sb.String()
+}
+
+// fillFromPool tops up any character the curator left short with a deterministic pick
+// from the pool, shuffled by the rotation period and character name
+func fillFromPool(lineup *CuratorResponse, characters []*Character, pool []MagicItem) {
+	missing := missingPicks(lineup, characters)
+	taken := takenNames(lineup)
+
+	for _, char := range characters {
+		need := missing[char.Name]
+		if need == 0 {
+			continue
+		}
+
+		order := make([]MagicItem, len(pool))
+		copy(order, pool)
+		h := fnv.New64a()
+		h.Write([]byte(CurrentRotationPeriod() + ":" + char.Name))
+		seed := h.Sum64()
+		r := rand.New(rand.NewPCG(seed, seed>>1|1))
+		r.Shuffle(len(order), func(a, b int) {
+			order[a], order[b] = order[b], order[a]
+		})
+
+		target := lineupSelection(lineup, char.Name)
+		for _, item := range order {
+			if need == 0 {
+				break
+			}
+			if taken[strings.ToLower(item.Name)] || !canPick(char, item) {
+				continue
+			}
+			taken[strings.ToLower(item.Name)] = true
+			target.Items = append(target.Items, CuratorItem{
+				Name:   item.Name,
+				Reason: "Picked from the shelves when the curator came up short",
+			})
+			need--
+		}
+		slog.Warn("filled missing curator picks from the pool",
+			"character", char.Name, "filled", missing[char.Name]-need)
+	}
 }
 
 // fuzzyMatchPool tries to match an LLM-returned name against the item pool.
@@ -263,7 +605,10 @@ func canonicalName(lowerName string, pool []MagicItem) string {
 	return lowerName
 }
 
-// selectionsToItems converts validated curator selections to shop Items with rolled prices
+// specialsStock is how many of each curated special the shop has on hand
+const specialsStock = 1
+
+// selectionsToItems converts validated curator selections to shop Items priced from the price book
 func selectionsToItems(response *CuratorResponse, pool []MagicItem) []Item {
 	// Build lookup map (lowercase name -> MagicItem)
 	poolMap := make(map[string]MagicItem, len(pool))
@@ -271,6 +616,9 @@ func selectionsToItems(response *CuratorResponse, pool []MagicItem) []Item {
 		poolMap[strings.ToLower(item.Name)] = item
 	}
 
+	// Each pick is reserved for the character it was curated for
+	holdUntil := time.Now().Add(config.HoldDuration).Format(time.RFC3339)
+
 	var items []Item
 	for _, sel := range response.Selections {
 		for _, ci := range sel.Items {
@@ -280,6 +628,10 @@ func selectionsToItems(response *CuratorResponse, pool []MagicItem) []Item {
 			}
 			shopItem := magicItem.ToShopItem()
 			shopItem.Category = "specials"
+			stock := specialsStock
+			shopItem.Stock = &stock
+			shopItem.HeldFor = sel.Character
+			shopItem.HoldUntil = holdUntil
 			shopItem.Description = fmt.Sprintf("Recommended for %s: %s | %s",
 				sel.Character, ci.Reason, magicItem.Description)
 			if magicItem.Attunement != "" {
@@ -292,52 +644,63 @@ func selectionsToItems(response *CuratorResponse, pool []MagicItem) []Item {
 	return items
 }
 
-// RefreshSessionSpecials uses LLM curation to generate personalized magic item recommendations
-func RefreshSessionSpecials() ([]Item, error) {
-	slog.Info("refreshing session specials via LLM curation")
-
-	// 1. Load all magic items
-	allItems, err := LoadAllMagicItems()
+// curateSpecials asks the curator LLM to pick items from the pool for each character,
+// narrowed to a per-character shortlist by embedding similarity when possible.
+// Picks dropped by validation are re-prompted for up to curatorRetries times, then any
+// remaining gaps are filled from the pool. Returns the picks and a hash of the prompt.
+func curateSpecials(characters []*Character, pool []MagicItem) (*CuratorResponse, string, error) {
+	// Send each character only their closest items, or a sample of what they can pick if
+	// embeddings are unavailable
+	pools, err := shortlistItems(characters, pool, config.Curator.ShortlistSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load magic items: %w", err)
+		slog.Warn("item shortlists unavailable, sending each character a sample of what they can pick", "error", err)
+		pools = eligiblePools(characters, pool, config.Curator.ShortlistSize)
 	}
-	slog.Info("loaded magic items", "count", len(allItems))
 
-	// 2. Load all characters
-	characters := GetAllCharacters()
-	if len(characters) == 0 {
-		return nil, fmt.Errorf("no characters found")
-	}
-	slog.Info("loaded characters", "count", len(characters))
+	conv := ai.NewConversation(curatorModel, curatorSystemPrompt)
+	userMsg := fitCuratorPools(characters, pools)
+	conv.AddMessage("user", userMsg)
 
-	// 3. Find minimum party level and filter by rarity
-	minLevel := 20
-	for _, char := range characters {
-		level := ParseLevel(char.ClassLevel)
-		if level < minLevel {
-			minLevel = level
+	lineup := &CuratorResponse{}
+	for attempt := 0; attempt <= curatorRetries; attempt++ {
+		round, err := askCurator(conv, len(characters))
+		if err != nil {
+			if attempt == 0 {
+				return nil, "", err
+			}
+			slog.Warn("curator re-prompt failed", "attempt", attempt, "error", err)
+			break
 		}
+
+		rejected := mergeSelections(lineup, round, characters, pool)
+		missing := missingPicks(lineup, characters)
+		if len(missing) == 0 || attempt == curatorRetries {
+			break
+		}
+
+		slog.Info("re-prompting curator for missing picks", "attempt", attempt+1, "characters", len(missing), "rejected", len(rejected))
+		conv.AddMessage("user", buildRefillMessage(characters, missing, rejected, lineup))
 	}
-	filtered := FilterItemsByLevel(allItems, minLevel)
-	slog.Info("filtered items by level", "min_level", minLevel, "eligible", len(filtered))
 
-	// 4. Build curator prompt and send to Ollama
-	conv := ai.NewConversation("llama3.1:8b", curatorSystemPrompt)
-	userMsg := buildCuratorMessage(characters, filtered)
-	conv.AddMessage("user", userMsg)
+	fillFromPool(lineup, characters, pool)
+	return lineup, HashPrompt(curatorSystemPrompt, userMsg), nil
+}
 
-	slog.Info("sending curator prompt to Ollama", "message_length", len(userMsg))
+// askCurator sends the conversation so far and parses the curator's JSON reply, with the
+// context size and timeout sized from the prompt
+func askCurator(conv *ai.Conversation, characters int) (*CuratorResponse, error) {
+	numCtx, timeout := curatorBudget(conv, characters)
+	slog.Info("sending curator prompt to Ollama", "messages", len(conv.Messages), "num_ctx", numCtx, "timeout", timeout)
 	start := time.Now()
 
-	rawResponse, err := conv.SendToOllamaWithTimeout(5*time.Minute, map[string]any{
-		"num_ctx": 8192,
+	rawResponse, err := conv.SendToOllamaWithTimeout(timeout, map[string]any{
+		"num_ctx": numCtx,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ollama curator call failed: %w", err)
 	}
 	slog.Info("ollama curator response received", "duration", time.Since(start))
 
-	// 5. Parse and validate response
 	jsonBytes, err := extractJSON(rawResponse)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse curator response: %w\nRaw response:\n%s", err, rawResponse)
@@ -347,24 +710,15 @@ func RefreshSessionSpecials() ([]Item, error) {
 	if err := json.Unmarshal(jsonBytes, &curatorResp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal curator response: %w", err)
 	}
+	return &curatorResp, nil
+}
 
-	validateSelections(&curatorResp, filtered)
-
-	// 6. Convert to shop Items with rolled prices
-	items := selectionsToItems(&curatorResp, filtered)
-	slog.Info("generated session specials", "item_count", len(items))
-
-	// 7. Write to session_specials.json
-	output := itemFile{Items: items}
-	data, err := json.MarshalIndent(output, "", "  ")
+// RefreshSessionSpecials uses LLM curation to generate personalized magic item recommendations
+// and publishes them straight to the live specials (no GM preview)
+func RefreshSessionSpecials() ([]Item, error) {
+	draft, err := generateSpecialsDraft()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal specials: %w", err)
+		return nil, err
 	}
-
-	if err := os.WriteFile(config.DataPaths.SessionSpecials, data, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write session specials: %w", err)
-	}
-
-	slog.Info("session specials written", "path", config.DataPaths.SessionSpecials)
-	return items, nil
+	return publishSpecials(draft)
 }
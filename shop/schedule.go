@@ -0,0 +1,245 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// maxRefreshRuns caps how many scheduled run records we keep
+const maxRefreshRuns = 50
+
+// Schedule is a parsed cron expression: minute hour day-of-month month day-of-week.
+// Supports "*", numbers, lists ("1,15"), ranges ("1-5") and steps ("*/15").
+type Schedule struct {
+	Expr    string
+	minutes map[int]bool
+	hours   map[int]bool
+	days    map[int]bool
+	months  map[int]bool
+	weekday map[int]bool
+	anyDay  bool // Day-of-month was "*"
+	anyDow  bool // Day-of-week was "*"
+}
+
+// cronField describes the valid range of one cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 7 is Sunday too, as in most crons
+}
+
+// weekdayNames lets day-of-week use names like "sun" as well as 0-7
+var weekdayNames = map[string]string{
+	"sun": "0", "mon": "1", "tue": "2", "wed": "3", "thu": "4", "fri": "5", "sat": "6",
+}
+
+// ParseSchedule parses a 5-field cron expression like "0 19 * * sun" (Sundays at 19:00)
+func ParseSchedule(expr string) (*Schedule, error) {
+	fields := strings.Fields(strings.ToLower(expr))
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("schedule '%s' needs %d fields (minute hour day month weekday)", expr, len(cronFields))
+	}
+
+	// Swap weekday names for numbers
+	for name, num := range weekdayNames {
+		fields[4] = strings.ReplaceAll(fields[4], name, num)
+	}
+
+	sets := make([]map[int]bool, len(cronFields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", expr, err)
+		}
+		sets[i] = set
+	}
+	if sets[4][7] {
+		delete(sets[4], 7)
+		sets[4][0] = true
+	}
+
+	return &Schedule{
+		Expr:    expr,
+		minutes: sets[0],
+		hours:   sets[1],
+		days:    sets[2],
+		months:  sets[3],
+		weekday: sets[4],
+		anyDay:  fields[2] == "*",
+		anyDow:  fields[4] == "*",
+	}, nil
+}
+
+// parseCronField expands one cron field into the set of values it matches
+func parseCronField(field string, spec cronField) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("bad step '%s' in %s", stepPart, spec.name)
+			}
+			step = n
+		}
+
+		lo, hi := spec.min, spec.max
+		if rangePart != "*" {
+			loStr, hiStr, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return nil, fmt.Errorf("bad value '%s' in %s", loStr, spec.name)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return nil, fmt.Errorf("bad value '%s' in %s", hiStr, spec.name)
+				}
+			} else if hasStep {
+				hi = spec.max
+			}
+		}
+		if lo < spec.min || hi > spec.max || lo > hi {
+			return nil, fmt.Errorf("%s '%s' out of range %d-%d", spec.name, part, spec.min, spec.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matches reports whether a time (to the minute) fits the schedule.
+// Like cron, if both day-of-month and day-of-week are restricted, either may match.
+func (s *Schedule) matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+	dayOK := s.days[t.Day()]
+	dowOK := s.weekday[int(t.Weekday())]
+	switch {
+	case s.anyDay && s.anyDow:
+		return true
+	case s.anyDay:
+		return dowOK
+	case s.anyDow:
+		return dayOK
+	default:
+		return dayOK || dowOK
+	}
+}
+
+// Next returns the first time strictly after t that matches the schedule.
+// Gives up after searching a year ahead (e.g. "0 0 31 2 *" never fires).
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.AddDate(1, 0, 0)
+	for next.Before(limit) {
+		if s.matches(next) {
+			return next, true
+		}
+		next = next.Add(time.Minute)
+	}
+	return time.Time{}, false
+}
+
+// RefreshRun records the outcome of one scheduled specials refresh
+type RefreshRun struct {
+	Started   string `json:"started"`
+	Finished  string `json:"finished"`
+	Status    string `json:"status"` // "ok" or "failed"
+	ItemCount int    `json:"item_count"`
+	Error     string `json:"error,omitempty"`
+}
+
+var refreshRunsMu sync.Mutex
+
+// LoadRefreshRuns loads the log of scheduled refresh runs, oldest first
+func LoadRefreshRuns() ([]RefreshRun, error) {
+	data, err := os.ReadFile(config.DataPaths.RefreshRuns)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RefreshRun{}, nil
+		}
+		return nil, fmt.Errorf("failed to read refresh runs: %w", err)
+	}
+
+	var file struct {
+		Runs []RefreshRun `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh runs: %w", err)
+	}
+	return file.Runs, nil
+}
+
+// RecordRefreshRun appends a run to the refresh log, keeping the most recent runs
+func RecordRefreshRun(run RefreshRun) error {
+	refreshRunsMu.Lock()
+	defer refreshRunsMu.Unlock()
+
+	runs, err := LoadRefreshRuns()
+	if err != nil {
+		return err
+	}
+	runs = append(runs, run)
+	if len(runs) > maxRefreshRuns {
+		runs = runs[len(runs)-maxRefreshRuns:]
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Runs []RefreshRun `json:"runs"`
+	}{runs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh runs: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.RefreshRuns, data, 0644); err != nil {
+		return fmt.Errorf("failed to write refresh runs: %w", err)
+	}
+	return nil
+}
+
+// FormatRefreshRuns returns the most recent scheduled refresh runs, newest first
+func FormatRefreshRuns(runs []RefreshRun, limit int) string {
+	if len(runs) == 0 {
+		return "No scheduled refreshes have run yet."
+	}
+
+	var sb strings.Builder
+	shown := 0
+	for i := len(runs) - 1; i >= 0 && shown < limit; i-- {
+		run := runs[i]
+		started, took := run.Started, ""
+		if start, err := time.Parse(time.RFC3339, run.Started); err == nil {
+			started = start.Local().Format("2006-01-02 15:04")
+			if end, err := time.Parse(time.RFC3339, run.Finished); err == nil {
+				took = fmt.Sprintf(" in %s", end.Sub(start))
+			}
+		}
+
+		if run.Status == "ok" {
+			sb.WriteString(fmt.Sprintf("• %s - ok%s, %d items\n", started, took, run.ItemCount))
+		} else {
+			sb.WriteString(fmt.Sprintf("• %s - **%s**%s: %s\n", started, run.Status, took, run.Error))
+		}
+		shown++
+	}
+	return sb.String()
+}
@@ -0,0 +1,162 @@
+package shop
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setValues returns the values in a cron field set, sorted
+func setValues(set map[int]bool) []int {
+	var values []int
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values
+}
+
+func TestParseCronField(t *testing.T) {
+	minute := cronFields[0]
+	dow := cronFields[4]
+	tests := []struct {
+		field string
+		spec  cronField
+		want  []int
+	}{
+		{"5", minute, []int{5}},
+		{"1,15,30", minute, []int{1, 15, 30}},
+		{"10-13", minute, []int{10, 11, 12, 13}},
+		{"*/15", minute, []int{0, 15, 30, 45}},
+		{"10-30/10", minute, []int{10, 20, 30}},
+		{"50/5", minute, []int{50, 55}},
+		{"1-3,20-21", minute, []int{1, 2, 3, 20, 21}},
+		{"*", dow, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		set, err := parseCronField(tt.field, tt.spec)
+		if err != nil {
+			t.Errorf("parseCronField(%q) error: %v", tt.field, err)
+			continue
+		}
+		got := setValues(set)
+		if len(got) != len(tt.want) {
+			t.Errorf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseCronField(%q) = %v, want %v", tt.field, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseScheduleWeekday(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"0 19 * * sun", []int{0}},
+		{"0 19 * * 7", []int{0}},
+		{"0 19 * * 0,7", []int{0}},
+		{"0 19 * * 5-7", []int{0, 5, 6}},
+		{"0 19 * * fri-sat", []int{5, 6}},
+		{"0 19 * * mon,wed,fri", []int{1, 3, 5}},
+		{"0 19 * * *", []int{0, 1, 2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error: %v", tt.expr, err)
+			continue
+		}
+		got := setValues(schedule.weekday)
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseSchedule(%q) weekdays = %v, want %v", tt.expr, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseSchedule(%q) weekdays = %v, want %v", tt.expr, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // Substring of the error
+	}{
+		{"", "needs 5 fields"},
+		{"0 19 * *", "needs 5 fields"},
+		{"0 19 * * * *", "needs 5 fields"},
+		{"60 * * * *", "minute '60' out of range 0-59"},
+		{"* 24 * * *", "hour '24' out of range 0-23"},
+		{"* * 0 * *", "day of month '0' out of range 1-31"},
+		{"* * * 13 *", "month '13' out of range 1-12"},
+		{"* * * * 8", "day of week '8' out of range 0-7"},
+		{"30-10 * * * *", "out of range"},
+		{"*/0 * * * *", "bad step '0'"},
+		{"*/x * * * *", "bad step 'x'"},
+		{"a * * * *", "bad value 'a'"},
+		{"1-b * * * *", "bad value 'b'"},
+		{"0 19 * * someday", "bad value"},
+	}
+	for _, tt := range tests {
+		_, err := ParseSchedule(tt.expr)
+		if err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error containing %q", tt.expr, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseSchedule(%q) error = %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Thursday 2026-01-15 10:30
+	from := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", from, time.Date(2026, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"* * * * *", from.Add(45 * time.Second), time.Date(2026, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", from, time.Date(2026, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", from, time.Date(2026, 1, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 19 * * sun", from, time.Date(2026, 1, 18, 19, 0, 0, 0, time.UTC)},
+		{"0 19 * * 7", from, time.Date(2026, 1, 18, 19, 0, 0, 0, time.UTC)},
+		{"0 9 1 * *", from, time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", from, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Day-of-month and day-of-week both restricted: either one fires
+		{"0 12 20 * mon", from, time.Date(2026, 1, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error: %v", tt.expr, err)
+		}
+		got, ok := schedule.Next(tt.from)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, %v, want %s", tt.expr, tt.from, got, ok, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextNever(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("ParseSchedule error: %v", err)
+	}
+	if next, ok := schedule.Next(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("Next = %s, want no run for February 31st", next)
+	}
+}
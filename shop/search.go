@@ -0,0 +1,268 @@
+package shop
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SearchResult is a single ranked match from a catalog search
+type SearchResult struct {
+	Item  Item
+	Score float64 // 0.0 - 1.0, 1.0 is an exact name match
+}
+
+// minSearchScore is the lowest score a candidate needs to be considered a match
+const minSearchScore = 0.55
+
+// ambiguityMargin is how close the runner-up has to be before we ask the player to pick
+const ambiguityMargin = 0.04
+
+// maxCandidates caps how many options we offer when a search is ambiguous
+const maxCandidates = 5
+
+// itemAliases maps common table-talk names to the catalog name they mean
+var itemAliases = map[string]string{
+	"healing potion":    "Potion of Healing",
+	"health potion":     "Potion of Healing",
+	"hp potion":         "Potion of Healing",
+	"greater healing":   "Potion of Healing (Greater)",
+	"superior healing":  "Potion of Healing (Superior)",
+	"supreme healing":   "Potion of Healing (Supreme)",
+	"plate":             "Plate Armor",
+	"full plate":        "Plate Armor",
+	"half plate":        "Half Plate Armor",
+	"chainmail":         "Chain Mail",
+	"chain armor":       "Chain Mail",
+	"studded leather":   "Studded Leather Armor",
+	"leather":           "Leather Armor",
+	"padded":            "Padded Armor",
+	"hide":              "Hide Armor",
+	"splint":            "Splint Armor",
+	"crossbow":          "Light Crossbow",
+	"bow":               "Shortbow",
+	"staff":             "Quarterstaff",
+	"short sword":       "Shortsword",
+	"long sword":        "Longsword",
+	"great sword":       "Greatsword",
+	"great axe":         "Greataxe",
+	"hand axe":          "Handaxe",
+	"war hammer":        "Warhammer",
+	"bolt case":         "Case, Crossbow Bolt",
+	"scroll case":       "Case, Map or Scroll",
+	"map case":          "Case, Map or Scroll",
+	"bullseye lantern":  "Lantern, Bullseye",
+	"hooded lantern":    "Lantern, Hooded",
+	"iron spikes":       "Spikes, Iron",
+	"iron pot":          "Pot, Iron",
+	"fine clothes":      "Clothes, Fine",
+	"travelers clothes": "Clothes, Traveler's",
+	"basic poison":      "Poison, Basic",
+	"glass bottle":      "Bottle, Glass",
+	"portable ram":      "Ram, Portable",
+	"whistle":           "Signal Whistle",
+}
+
+// searchStopWords are ignored when comparing tokens ("potion of healing" vs "healing potion")
+var searchStopWords = map[string]bool{
+	"of":  true,
+	"the": true,
+	"a":   true,
+	"an":  true,
+	"and": true,
+}
+
+// AmbiguousItemError is returned by FindItem when several items match about equally well
+type AmbiguousItemError struct {
+	Query      string
+	Candidates []Item
+}
+
+func (e *AmbiguousItemError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, item := range e.Candidates {
+		names[i] = item.Name
+	}
+	return fmt.Sprintf("'%s' matches several items: %s", e.Query, strings.Join(names, ", "))
+}
+
+// SearchItems ranks catalog items and session specials against a query.
+// Results are sorted best first and capped at limit (0 means no cap).
+func (c *Catalog) SearchItems(query string, limit int) []SearchResult {
+	q := normalizeSearchText(query)
+	if q == "" {
+		return nil
+	}
+
+	// Resolve aliases up front so "hp potion" scores as "potion of healing"
+	if target, ok := itemAliases[q]; ok {
+		q = normalizeSearchText(target)
+	}
+
+	seen := make(map[string]bool)
+	var results []SearchResult
+	for _, item := range c.allSearchableItems() {
+		key := strings.ToLower(item.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		score := scoreItemName(q, item.Name)
+		if score >= minSearchScore {
+			results = append(results, SearchResult{Item: item, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Score > results[b].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// allSearchableItems returns the session specials followed by the catalog. Specials come
+// first so a special shadows the catalog copy of the same magic item, with its own price,
+// stock and hold.
+func (c *Catalog) allSearchableItems() []Item {
+	items := make([]Item, 0, len(c.Items)+len(c.SessionSpecials.Items))
+	items = append(items, c.SessionSpecials.Items...)
+	items = append(items, c.Items...)
+	return items
+}
+
+// normalizeSearchText lowercases and strips punctuation so "Alchemist's Fire" == "alchemists fire"
+func normalizeSearchText(s string) string {
+	s = strings.ToLower(s)
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r == '\'' || r == '’':
+			// Drop apostrophes entirely: "thieves'" -> "thieves"
+		default:
+			sb.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// searchTokens splits normalized text into tokens, dropping stop words
+func searchTokens(s string) []string {
+	var tokens []string
+	for _, t := range strings.Fields(s) {
+		if !searchStopWords[t] {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// scoreItemName scores how well a normalized query matches an item name.
+// Combines whole-string edit distance, substring containment and per-token overlap.
+func scoreItemName(query, name string) float64 {
+	n := normalizeSearchText(name)
+	if query == n {
+		return 1.0
+	}
+
+	// Whole-string similarity catches typos like "longsord"
+	best := similarity(query, n)
+
+	// Substring match: "crossbow" in "light crossbow"
+	if len(query) >= 3 && strings.Contains(n, query) {
+		contains := 0.8 + 0.15*float64(len(query))/float64(len(n))
+		if contains > best {
+			best = contains
+		}
+	}
+
+	// Token overlap handles word order and partial names: "healing potion"
+	if tokenScore := tokenOverlapScore(searchTokens(query), searchTokens(n)); tokenScore > best {
+		best = tokenScore
+	}
+
+	// Only an exact match gets a perfect score
+	if best > 0.99 {
+		best = 0.99
+	}
+	return best
+}
+
+// tokenOverlapScore rates how many query tokens appear (possibly misspelled) in the name,
+// with a small penalty for name tokens the query didn't mention
+func tokenOverlapScore(queryTokens, nameTokens []string) float64 {
+	if len(queryTokens) == 0 || len(nameTokens) == 0 {
+		return 0
+	}
+
+	matchedName := make(map[int]bool)
+	var total float64
+	for _, qt := range queryTokens {
+		bestToken, bestIdx := 0.0, -1
+		for idx, nt := range nameTokens {
+			var s float64
+			switch {
+			case qt == nt:
+				s = 1.0
+			case len(qt) >= 3 && strings.HasPrefix(nt, qt):
+				s = 0.9
+			default:
+				s = similarity(qt, nt)
+				if s < 0.75 {
+					s = 0
+				}
+			}
+			if s > bestToken {
+				bestToken, bestIdx = s, idx
+			}
+		}
+		if bestIdx >= 0 {
+			matchedName[bestIdx] = true
+		}
+		total += bestToken
+	}
+
+	coverage := total / float64(len(queryTokens))
+	nameFraction := float64(len(matchedName)) / float64(len(nameTokens))
+	return coverage * (0.85 + 0.1*nameFraction)
+}
+
+// similarity returns 1 - normalized Levenshtein distance between two strings
+func similarity(a, b string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1.0
+	}
+	return 1.0 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,157 @@
+package shop
+
+import (
+	"errors"
+	"testing"
+)
+
+// testCatalog is a small catalog with the kinds of near-duplicate names the real one has
+func testCatalog() *Catalog {
+	names := []string{
+		"Longsword",
+		"Shortsword",
+		"Greatsword",
+		"Light Crossbow",
+		"Heavy Crossbow",
+		"Hand Crossbow",
+		"Potion of Healing",
+		"Potion of Healing (Greater)",
+		"Plate Armor",
+		"Half Plate Armor",
+		"Chain Mail",
+		"Chain Shirt",
+		"Alchemist's Fire",
+		"Case, Map or Scroll",
+		"Thieves' Tools",
+	}
+	catalog := &Catalog{}
+	for _, name := range names {
+		catalog.Items = append(catalog.Items, Item{Name: name, Category: "test", Cost: 1})
+	}
+	return catalog
+}
+
+func TestFindItem(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		// Exact matches, ignoring case and punctuation
+		{"Longsword", "Longsword"},
+		{"longsword", "Longsword"},
+		{"alchemists fire", "Alchemist's Fire"},
+		{"Thieves Tools", "Thieves' Tools"},
+		{"potion of healing", "Potion of Healing"},
+		// Aliases
+		{"healing potion", "Potion of Healing"},
+		{"hp potion", "Potion of Healing"},
+		{"greater healing", "Potion of Healing (Greater)"},
+		{"plate", "Plate Armor"},
+		{"half plate", "Half Plate Armor"},
+		{"chainmail", "Chain Mail"},
+		{"long sword", "Longsword"},
+		{"crossbow", "Light Crossbow"},
+		{"scroll case", "Case, Map or Scroll"},
+		// Typos
+		{"longsord", "Longsword"},
+		{"greatswrod", "Greatsword"},
+		{"heavy crosbow", "Heavy Crossbow"},
+		{"alchemist fire", "Alchemist's Fire"},
+		// Word order
+		{"crossbow heavy", "Heavy Crossbow"},
+	}
+	catalog := testCatalog()
+	for _, tt := range tests {
+		item, err := catalog.FindItem(tt.query)
+		if err != nil {
+			t.Errorf("FindItem(%q) error: %v", tt.query, err)
+			continue
+		}
+		if item.Name != tt.want {
+			t.Errorf("FindItem(%q) = %s, want %s", tt.query, item.Name, tt.want)
+		}
+	}
+}
+
+func TestFindItemAmbiguous(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string // Candidates offered, in any order
+	}{
+		{"chain", []string{"Chain Mail", "Chain Shirt"}},
+		{"sword", []string{"Longsword", "Shortsword", "Greatsword"}},
+	}
+	catalog := testCatalog()
+	for _, tt := range tests {
+		_, err := catalog.FindItem(tt.query)
+		var ambiguous *AmbiguousItemError
+		if !errors.As(err, &ambiguous) {
+			t.Errorf("FindItem(%q) error = %v, want an AmbiguousItemError", tt.query, err)
+			continue
+		}
+		if len(ambiguous.Candidates) > maxCandidates {
+			t.Errorf("FindItem(%q) offered %d candidates, want at most %d", tt.query, len(ambiguous.Candidates), maxCandidates)
+		}
+		offered := make(map[string]bool)
+		for _, item := range ambiguous.Candidates {
+			offered[item.Name] = true
+		}
+		for _, name := range tt.want {
+			if !offered[name] {
+				t.Errorf("FindItem(%q) candidates = %v, want them to include %s", tt.query, ambiguous.Candidates, name)
+			}
+		}
+	}
+}
+
+func TestFindItemNotFound(t *testing.T) {
+	catalog := testCatalog()
+	for _, query := range []string{"", "   ", "bag of holding", "zzzz", "rope"} {
+		item, err := catalog.FindItem(query)
+		if err == nil {
+			t.Errorf("FindItem(%q) = %s, want not found", query, item.Name)
+			continue
+		}
+		var ambiguous *AmbiguousItemError
+		if errors.As(err, &ambiguous) {
+			t.Errorf("FindItem(%q) was ambiguous, want not found", query)
+		}
+	}
+}
+
+func TestSearchItemsThreshold(t *testing.T) {
+	catalog := testCatalog()
+	for _, query := range []string{"longsword", "crossbow", "potion", "zzzz"} {
+		for _, r := range catalog.SearchItems(query, 0) {
+			if r.Score < minSearchScore {
+				t.Errorf("SearchItems(%q) returned %s at %.2f, below the %.2f threshold", query, r.Item.Name, r.Score, minSearchScore)
+			}
+		}
+	}
+
+	results := catalog.SearchItems("crossbow", 2)
+	if len(results) != 2 {
+		t.Fatalf("SearchItems(crossbow, 2) returned %d results, want 2", len(results))
+	}
+	if results[0].Score < results[1].Score {
+		t.Errorf("SearchItems(crossbow) not sorted best first: %.2f then %.2f", results[0].Score, results[1].Score)
+	}
+	if exact := catalog.SearchItems("Longsword", 1); len(exact) != 1 || exact[0].Score != 1.0 {
+		t.Errorf("SearchItems(Longsword) = %v, want an exact match scoring 1.0", exact)
+	}
+}
+
+func TestSearchItemsSpecialsFirst(t *testing.T) {
+	catalog := testCatalog()
+	stock := 1
+	catalog.Items = append(catalog.Items, Item{Name: "Bag of Holding", Category: "wondrous", Cost: 500})
+	catalog.SessionSpecials.Items = []Item{{Name: "Bag of Holding", Category: "specials", Cost: 450, Stock: &stock}}
+
+	results := catalog.SearchItems("bag of holding", 0)
+	if len(results) != 1 {
+		t.Fatalf("SearchItems(bag of holding) returned %d results, want the special alone", len(results))
+	}
+	if results[0].Item.Category != "specials" {
+		t.Errorf("SearchItems(bag of holding) = %s copy, want the special", results[0].Item.Category)
+	}
+}
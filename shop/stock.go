@@ -0,0 +1,237 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// SoldOutError is returned when a purchase asks for more than the shop has left
+type SoldOutError struct {
+	Item      string
+	Remaining int
+}
+
+func (e *SoldOutError) Error() string {
+	if e.Remaining <= 0 {
+		return fmt.Sprintf("'%s' is sold out", e.Item)
+	}
+	return fmt.Sprintf("only %d of '%s' left", e.Remaining, e.Item)
+}
+
+// loadStockLedger reads remaining counts for limited catalog items (item name -> remaining)
+func loadStockLedger() (map[string]int, error) {
+	ledger := make(map[string]int)
+
+	data, err := os.ReadFile(config.DataPaths.Stock)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+		return nil, fmt.Errorf("failed to read stock ledger: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, fmt.Errorf("failed to parse stock ledger: %w", err)
+	}
+	return ledger, nil
+}
+
+// saveStockLedger writes remaining counts for limited catalog items
+func saveStockLedger(ledger map[string]int) error {
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stock ledger: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.Stock, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stock ledger: %w", err)
+	}
+	return nil
+}
+
+// applyStockLedger replaces the starting stock of limited items with what's left in the ledger
+func applyStockLedger(items []Item) error {
+	ledger, err := loadStockLedger()
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		if items[i].Stock == nil {
+			continue
+		}
+		if remaining, ok := ledger[items[i].Name]; ok {
+			items[i].Stock = &remaining
+		}
+	}
+	return nil
+}
+
+// adjustStock changes the stock of a limited item by delta (negative to sell).
+// Specials are tracked in the specials file itself, catalog items in the stock ledger.
+// Unlimited items are left alone. Callers must hold purchaseMu.
+func adjustStock(item Item, delta int) error {
+	if item.Category == "specials" {
+		return adjustSpecialsStock(item.Name, delta)
+	}
+	if item.Stock == nil {
+		return nil
+	}
+
+	ledger, err := loadStockLedger()
+	if err != nil {
+		return err
+	}
+
+	remaining, ok := ledger[item.Name]
+	if !ok {
+		remaining = *item.Stock
+	}
+	if remaining+delta < 0 {
+		return &SoldOutError{Item: item.Name, Remaining: remaining}
+	}
+
+	ledger[item.Name] = remaining + delta
+	return saveStockLedger(ledger)
+}
+
+// adjustSpecialsStock changes the stock of a session special in the specials file
+func adjustSpecialsStock(name string, delta int) error {
+	specials, err := loadItemsFromFile(config.DataPaths.SessionSpecials, "specials")
+	if err != nil {
+		return fmt.Errorf("failed to load session specials: %w", err)
+	}
+
+	for idx := range specials {
+		if !strings.EqualFold(specials[idx].Name, name) {
+			continue
+		}
+		if specials[idx].Stock == nil {
+			return nil
+		}
+		remaining := *specials[idx].Stock
+		if remaining+delta < 0 {
+			return &SoldOutError{Item: specials[idx].Name, Remaining: remaining}
+		}
+		remaining += delta
+		specials[idx].Stock = &remaining
+		return writeSessionSpecials(specials)
+	}
+
+	return fmt.Errorf("'%s' is no longer in the session specials", name)
+}
+
+// writeSessionSpecials replaces the live session specials file
+func writeSessionSpecials(items []Item) error {
+	data, err := json.MarshalIndent(itemFile{Items: items}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal specials: %w", err)
+	}
+
+	if err := os.WriteFile(config.DataPaths.SessionSpecials, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session specials: %w", err)
+	}
+	return nil
+}
+
+// LevelTooLowError is returned when a character tries to buy (or haggle for) an item whose
+// rarity is above what their level allows
+type LevelTooLowError struct {
+	Item   string
+	Rarity string
+	Level  int
+}
+
+func (e *LevelTooLowError) Error() string {
+	return fmt.Sprintf("'%s' is %s, which a level %d character can't buy yet", e.Item, strings.ToLower(normalizeRarity(e.Rarity)), e.Level)
+}
+
+// checkLevel refuses items whose rarity is above what the level allows
+func checkLevel(item Item, level int) error {
+	if !CanBuyAtLevel(item, level) {
+		return &LevelTooLowError{Item: item.Name, Rarity: item.Rarity, Level: level}
+	}
+	return nil
+}
+
+// HeldItemError is returned when a special is reserved for another character
+type HeldItemError struct {
+	Item    string
+	HeldFor string
+	Until   string
+}
+
+func (e *HeldItemError) Error() string {
+	until := e.Until
+	if t, err := time.Parse(time.RFC3339, e.Until); err == nil {
+		until = t.Local().Format("Mon Jan 2 15:04")
+	}
+	return fmt.Sprintf("'%s' is on hold for %s until %s", e.Item, e.HeldFor, until)
+}
+
+// checkHold refuses purchases of specials held for another character. Matches by name
+// whatever the item's category, so the catalog copy of a held magic item is held too.
+// Reads the live specials file so a hold released by the GM takes effect immediately.
+// Callers must hold purchaseMu.
+func checkHold(item Item, characterName string) error {
+	specials, err := loadItemsFromFile(config.DataPaths.SessionSpecials, "specials")
+	if err != nil {
+		return fmt.Errorf("failed to load session specials: %w", err)
+	}
+	for _, special := range specials {
+		if strings.EqualFold(special.Name, item.Name) && special.HeldFromCharacter(characterName, time.Now()) {
+			return &HeldItemError{Item: special.Name, HeldFor: special.HeldFor, Until: special.HoldUntil}
+		}
+	}
+	return nil
+}
+
+// GetHeldSpecials returns the session specials currently on hold
+func GetHeldSpecials() []Item {
+	var held []Item
+	now := time.Now()
+	for _, item := range GetSessionSpecials() {
+		if item.HoldActive(now) {
+			held = append(held, item)
+		}
+	}
+	return held
+}
+
+// ReleaseHolds clears holds on session specials so anyone can buy them.
+// An empty itemName releases every hold. Returns the names of items released.
+func ReleaseHolds(itemName string) ([]string, error) {
+	purchaseMu.Lock()
+	defer purchaseMu.Unlock()
+
+	specials, err := loadItemsFromFile(config.DataPaths.SessionSpecials, "specials")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session specials: %w", err)
+	}
+
+	var released []string
+	now := time.Now()
+	for idx := range specials {
+		if itemName != "" && !strings.EqualFold(specials[idx].Name, itemName) {
+			continue
+		}
+		if specials[idx].HoldActive(now) {
+			released = append(released, specials[idx].Name)
+		}
+		specials[idx].HeldFor = ""
+		specials[idx].HoldUntil = ""
+	}
+
+	if itemName != "" && len(released) == 0 {
+		return nil, fmt.Errorf("'%s' isn't on hold", itemName)
+	}
+	if err := writeSessionSpecials(specials); err != nil {
+		return nil, err
+	}
+	return released, nil
+}
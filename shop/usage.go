@@ -0,0 +1,238 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+	"github.com/egotch/dnd-shopkeep/dice"
+)
+
+// UsageRecord is one consumable a character used up
+type UsageRecord struct {
+	Date  string `json:"date"`
+	Item  string `json:"item"`
+	Kind  string `json:"kind,omitempty"`  // "healing", "damage" or "effect" when dice were rolled
+	Roll  string `json:"roll,omitempty"`  // The dice rolled, e.g. "2d4 (3, 1) + 2 = 6"
+	Total int    `json:"total,omitempty"` // The rolled total
+}
+
+// UsageLog is a character's record of consumables used
+type UsageLog struct {
+	Character string        `json:"character"`
+	Uses      []UsageRecord `json:"uses"`
+}
+
+// UseResult is the outcome of using a consumable
+type UseResult struct {
+	Item      Item   // The catalog item, or just the name if it isn't in the catalog
+	Entry     string // The inventory entry it came from
+	Remaining int    // How many of the item are left in the inventory
+	Kind      string // "healing", "damage" or "effect"; empty if nothing was rolled
+	Roll      *dice.Result
+}
+
+// NotConsumableError is returned when a character tries to use up an item that isn't consumable
+type NotConsumableError struct {
+	Item string
+}
+
+func (e *NotConsumableError) Error() string {
+	return fmt.Sprintf("'%s' isn't used up when you use it", e.Item)
+}
+
+// inventoryCountRegex reads a count on an inventory entry: "3x Torch", "Arrows (20)" or "Rations x5"
+var inventoryCountRegex = regexp.MustCompile(`^(?:(\d+)\s*[x×]?\s+)?(.+?)(?:\s*\((\d+)\)|\s+[x×]\s*(\d+))?$`)
+
+// parseInventoryEntry splits an inventory entry into the item name and how many it stands
+// for. span is the position of the count in the entry, or nil if it has none.
+func parseInventoryEntry(entry string) (name string, count int, span []int) {
+	match := inventoryCountRegex.FindStringSubmatchIndex(entry)
+	if match == nil {
+		return entry, 1, nil
+	}
+	name = entry[match[4]:match[5]]
+	for group := 1; group <= 4; group++ {
+		if group == 2 || match[2*group] < 0 {
+			continue
+		}
+		span = match[2*group : 2*group+2]
+		count, _ = strconv.Atoi(entry[span[0]:span[1]])
+		return name, count, span
+	}
+	return name, 1, nil
+}
+
+// countInInventory returns how many of an item a character is carrying across all entries
+func countInInventory(char *Character, name string) int {
+	total := 0
+	for _, entry := range char.CurrentInventory {
+		if entryName, count, _ := parseInventoryEntry(entry); strings.EqualFold(entryName, name) {
+			total += count
+		}
+	}
+	return total
+}
+
+// findInventoryEntry returns the index of the inventory entry that best matches a name, or -1
+func findInventoryEntry(char *Character, name string) int {
+	query := normalizeSearchText(name)
+	best, bestScore := -1, carriedMatchScore
+	for idx, entry := range char.CurrentInventory {
+		entryName, _, _ := parseInventoryEntry(entry)
+		if score := scoreItemName(query, entryName); score >= bestScore {
+			best, bestScore = idx, score
+		}
+	}
+	return best
+}
+
+// carriedItem matches an inventory entry's name against the catalog. Items the catalog
+// doesn't know come back with just their name, so IsConsumable judges them by name alone.
+func (c *Catalog) carriedItem(name string) Item {
+	if results := c.SearchItems(name, 1); len(results) > 0 && results[0].Score >= carriedMatchScore {
+		return results[0].Item
+	}
+	return Item{Name: name}
+}
+
+// UsableItem is a consumable a character is carrying
+type UsableItem struct {
+	Name  string
+	Count int
+}
+
+// UsableItems lists the consumables in a character's inventory and how many of each they carry
+func UsableItems(char *Character) ([]UsableItem, error) {
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	var usable []UsableItem
+	seen := make(map[string]int)
+	for _, entry := range char.CurrentInventory {
+		name, count, _ := parseInventoryEntry(entry)
+		key := strings.ToLower(name)
+		if idx, ok := seen[key]; ok {
+			usable[idx].Count += count
+			continue
+		}
+		if !catalog.carriedItem(name).IsConsumable() {
+			continue
+		}
+		seen[key] = len(usable)
+		usable = append(usable, UsableItem{Name: name, Count: count})
+	}
+	return usable, nil
+}
+
+// LoadUsageLog loads the consumable usage log for a character
+func LoadUsageLog(characterFile string) (*UsageLog, error) {
+	filename := filepath.Join(config.DataPaths.Usage, characterFile+".json")
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		// If file doesn't exist, return empty log
+		if os.IsNotExist(err) {
+			return &UsageLog{
+				Character: characterFile,
+				Uses:      []UsageRecord{},
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read usage log for '%s': %w", characterFile, err)
+	}
+
+	var usage UsageLog
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("failed to parse usage log for '%s': %w", characterFile, err)
+	}
+
+	return &usage, nil
+}
+
+// SaveUsageLog saves the usage log to file
+func SaveUsageLog(usage *UsageLog) error {
+	if err := os.MkdirAll(config.DataPaths.Usage, 0755); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+
+	filename := filepath.Join(config.DataPaths.Usage, usage.Character+".json")
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage log: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+
+	return nil
+}
+
+// UseItem uses up one consumable from a character's inventory. It rolls the item's healing
+// or effect dice if its description has any, takes one off the inventory entry (removing the
+// entry when it's the last), and logs the use.
+func UseItem(characterFile, name string) (*UseResult, error) {
+	purchaseMu.Lock()
+	defer purchaseMu.Unlock()
+
+	char, err := loadCharacterFile(characterFile)
+	if err != nil {
+		return nil, err
+	}
+	idx := findInventoryEntry(char, name)
+	if idx < 0 {
+		return nil, fmt.Errorf("'%s' isn't in %s's inventory", name, char.Name)
+	}
+	entry := char.CurrentInventory[idx]
+	entryName, count, span := parseInventoryEntry(entry)
+
+	catalog, err := LoadCatalog()
+	if err != nil {
+		return nil, err
+	}
+	item := catalog.carriedItem(entryName)
+	if !item.IsConsumable() {
+		return nil, &NotConsumableError{Item: entryName}
+	}
+
+	result := &UseResult{Item: item, Entry: entry}
+	record := UsageRecord{Date: time.Now().Format("2006-01-02"), Item: entryName}
+	if expr, kind, ok := ItemDice(item); ok {
+		roll := expr.Roll()
+		result.Kind, result.Roll = kind, &roll
+		record.Kind, record.Roll, record.Total = kind, roll.String(), roll.Total
+	}
+
+	original := char.CurrentInventory
+	inventory := append([]string{}, original...)
+	if count > 1 && span != nil {
+		inventory[idx] = entry[:span[0]] + strconv.Itoa(count-1) + entry[span[1]:]
+	} else {
+		inventory = append(inventory[:idx], inventory[idx+1:]...)
+	}
+	char.CurrentInventory = inventory
+	if err := SaveCharacter(characterFile, char); err != nil {
+		char.CurrentInventory = original
+		return nil, err
+	}
+	result.Remaining = countInInventory(char, entryName)
+
+	usage, err := LoadUsageLog(characterFile)
+	if err == nil {
+		usage.Uses = append(usage.Uses, record)
+		err = SaveUsageLog(usage)
+	}
+	if err != nil {
+		// The item is already gone from the inventory; a missing log line isn't worth undoing that
+		slog.Warn("failed to log item use", "character", characterFile, "item", entryName, "error", err)
+	}
+	return result, nil
+}
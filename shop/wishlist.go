@@ -0,0 +1,226 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egotch/dnd-shopkeep/config"
+)
+
+// maxNotifiedLineups caps how many lineup keys we remember per wishlist entry
+const maxNotifiedLineups = 10
+
+// wishlistMu serializes wishlist read-modify-writes, so a notification being marked
+// can't overwrite an item being added or removed at the same time
+var wishlistMu sync.Mutex
+
+// WishlistEntry is one item a character wants the shop to stock
+type WishlistEntry struct {
+	Item     string   `json:"item"`
+	Added    string   `json:"added"`
+	Notified []string `json:"notified,omitempty"` // Lineup keys we've already pinged about
+}
+
+// Wishlist represents a character's wanted items
+type Wishlist struct {
+	Character     string          `json:"character"`
+	DiscordUserID string          `json:"discord_user_id,omitempty"` // For restock DMs
+	Items         []WishlistEntry `json:"items"`
+}
+
+// WishlistMatch is a wishlisted item that just showed up in stock
+type WishlistMatch struct {
+	Character     string // The character file the wishlist belongs to
+	DiscordUserID string
+	Item          Item
+}
+
+// LoadWishlist loads the wishlist for a character
+func LoadWishlist(characterFile string) (*Wishlist, error) {
+	filename := filepath.Join(config.DataPaths.Wishlists, characterFile+".json")
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		// If file doesn't exist, return empty wishlist
+		if os.IsNotExist(err) {
+			return &Wishlist{
+				Character: characterFile,
+				Items:     []WishlistEntry{},
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read wishlist for '%s': %w", characterFile, err)
+	}
+
+	var wishlist Wishlist
+	if err := json.Unmarshal(data, &wishlist); err != nil {
+		return nil, fmt.Errorf("failed to parse wishlist for '%s': %w", characterFile, err)
+	}
+
+	return &wishlist, nil
+}
+
+// SaveWishlist saves the wishlist to file
+func SaveWishlist(wishlist *Wishlist) error {
+	if err := os.MkdirAll(config.DataPaths.Wishlists, 0755); err != nil {
+		return fmt.Errorf("failed to create wishlist directory: %w", err)
+	}
+
+	filename := filepath.Join(config.DataPaths.Wishlists, wishlist.Character+".json")
+	data, err := json.MarshalIndent(wishlist, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal wishlist: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write wishlist: %w", err)
+	}
+
+	return nil
+}
+
+// AddToWishlist adds an item to a character's wishlist, remembering their Discord user ID for DMs
+func AddToWishlist(characterFile, discordUserID, itemName string) error {
+	wishlistMu.Lock()
+	defer wishlistMu.Unlock()
+
+	wishlist, err := LoadWishlist(characterFile)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range wishlist.Items {
+		if strings.EqualFold(entry.Item, itemName) {
+			return fmt.Errorf("'%s' is already on your wishlist", entry.Item)
+		}
+	}
+
+	if discordUserID != "" {
+		wishlist.DiscordUserID = discordUserID
+	}
+	wishlist.Items = append(wishlist.Items, WishlistEntry{
+		Item:  itemName,
+		Added: time.Now().Format("2006-01-02"),
+	})
+	return SaveWishlist(wishlist)
+}
+
+// RemoveFromWishlist removes an item from a character's wishlist
+func RemoveFromWishlist(characterFile, itemName string) error {
+	wishlistMu.Lock()
+	defer wishlistMu.Unlock()
+
+	wishlist, err := LoadWishlist(characterFile)
+	if err != nil {
+		return err
+	}
+
+	for idx, entry := range wishlist.Items {
+		if strings.EqualFold(entry.Item, itemName) {
+			wishlist.Items = append(wishlist.Items[:idx], wishlist.Items[idx+1:]...)
+			return SaveWishlist(wishlist)
+		}
+	}
+	return fmt.Errorf("'%s' is not on your wishlist", itemName)
+}
+
+// FindWishlistMatches checks every wishlist against a lineup of in-stock items.
+// lineupKey identifies the lineup (e.g. "monthly:2026-01"); entries already marked
+// for that key are skipped. Matches aren't marked here: call MarkWishlistNotified once
+// the player has actually been told, so a failed notice is retried on the next check.
+func FindWishlistMatches(items []Item, lineupKey string) ([]WishlistMatch, error) {
+	wishlistMu.Lock()
+	defer wishlistMu.Unlock()
+
+	entries, err := os.ReadDir(config.DataPaths.Wishlists)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read wishlists: %w", err)
+	}
+
+	inStock := make(map[string]Item, len(items))
+	for _, item := range items {
+		inStock[strings.ToLower(item.Name)] = item
+	}
+
+	var matches []WishlistMatch
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		characterFile := strings.TrimSuffix(entry.Name(), ".json")
+		wishlist, err := LoadWishlist(characterFile)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, wanted := range wishlist.Items {
+			item, ok := inStock[strings.ToLower(wanted.Item)]
+			if !ok || wanted.notifiedFor(lineupKey) {
+				continue
+			}
+			matches = append(matches, WishlistMatch{
+				Character:     characterFile,
+				DiscordUserID: wishlist.DiscordUserID,
+				Item:          item,
+			})
+		}
+	}
+
+	return matches, nil
+}
+
+// MarkWishlistNotified records that a character was told about a wishlisted item for a
+// lineup, so later checks of the same lineup don't notify them again
+func MarkWishlistNotified(characterFile, itemName, lineupKey string) error {
+	wishlistMu.Lock()
+	defer wishlistMu.Unlock()
+
+	wishlist, err := LoadWishlist(characterFile)
+	if err != nil {
+		return err
+	}
+
+	for idx := range wishlist.Items {
+		wanted := &wishlist.Items[idx]
+		if !strings.EqualFold(wanted.Item, itemName) || wanted.notifiedFor(lineupKey) {
+			continue
+		}
+		wanted.Notified = append(wanted.Notified, lineupKey)
+		if len(wanted.Notified) > maxNotifiedLineups {
+			wanted.Notified = wanted.Notified[len(wanted.Notified)-maxNotifiedLineups:]
+		}
+		return SaveWishlist(wishlist)
+	}
+	// Removed from the wishlist since the match was found; nothing to mark
+	return nil
+}
+
+// notifiedFor reports whether this entry was already matched for a lineup
+func (e *WishlistEntry) notifiedFor(lineupKey string) bool {
+	for _, key := range e.Notified {
+		if key == lineupKey {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatWishlist returns a formatted string of the wishlist
+func (w *Wishlist) FormatWishlist() string {
+	if len(w.Items) == 0 {
+		return "Your wishlist is empty. Use /wishlist add to ask Grash to keep an eye out."
+	}
+
+	var sb strings.Builder
+	for _, entry := range w.Items {
+		sb.WriteString(fmt.Sprintf("• **%s** (added %s)\n", entry.Item, entry.Added))
+	}
+	return sb.String()
+}